@@ -0,0 +1,201 @@
+package circonus
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Structures ============================================================ //
+
+// An Alert is raised when a metric violates one of the rules of a rule set,
+// and is cleared once the metric no longer does.  Alerts are created by
+// Circonus and may only be read.
+type Alert struct {
+	CID                CID      `json:"_cid"`
+	AcknowledgementCID *CID     `json:"_acknowledgement"` // Nil unless acknowledged
+	AlertURL           string   `json:"_alert_url"`
+	BrokerCID          CID      `json:"_broker"`
+	CheckCID           CID      `json:"_check"`
+	CheckName          string   `json:"_check_name"`
+	ClearedOn          *int64   `json:"_cleared_on"` // Nil while the alert is active
+	ClearedValue       *string  `json:"_cleared_value"`
+	Maintenance        []CID    `json:"_maintenance"`
+	MetricLink         *string  `json:"_metric_link"`
+	MetricName         string   `json:"_metric_name"`
+	MetricNotes        *string  `json:"_metric_notes"`
+	OccurredOn         int64    `json:"_occurred_on"`
+	RuleSetCID         CID      `json:"_rule_set"`
+	Severity           int      `json:"_severity"`
+	Tags               []string `json:"_tags"`
+	Value              string   `json:"_value"` // Value of the metric when raised
+}
+
+// An Acknowledgement silences further notifications of an alert until a
+// given time.
+//
+// Times are given in seconds since the epoch.
+type Acknowledgement struct {
+	CID               CID    `json:"_cid,omitempty"`
+	AcknowledgedBy    CID    `json:"_acknowledged_by,omitempty"`
+	AcknowledgedOn    int64  `json:"_acknowledged_on,omitempty"`
+	Active            bool   `json:"_active,omitempty"`
+	LastModified      int64  `json:"_last_modified,omitempty"`
+	LastModifiedBy    string `json:"_last_modified_by,omitempty"`
+	AcknowledgedUntil int64  `json:"acknowledged_until"`
+	AlertCID          CID    `json:"alert"`
+	Notes             string `json:"notes"`
+}
+
+// An AlertFilter selects alerts returned by ListAlerts.  The zero value
+// selects every alert.
+type AlertFilter struct {
+	Active     bool     // Only alerts which have not cleared
+	Cleared    bool     // Only alerts which have cleared
+	Severities []int    // Only alerts of these severities
+	Tags       []string // Only alerts with all of these tags
+}
+
+// Alert Helpers ========================================================= //
+
+// Reports whether an alert has yet to clear.
+func (a Alert) Active() bool {
+	return a.ClearedOn == nil
+}
+
+// Reports whether an alert matches every criteria of a filter.
+func (f AlertFilter) Matches(a Alert) bool {
+	if f.Active && !a.Active() {
+		return false
+	}
+	if f.Cleared && a.Active() {
+		return false
+	}
+	if len(f.Severities) > 0 {
+		found := false
+		for _, severity := range f.Severities {
+			found = found || severity == a.Severity
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, tag := range f.Tags {
+		if !contains(a.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// Alert API ============================================================= //
+
+// Retrieves the alert with the given CID (eg. "/alert/1234").
+func (c *Client) GetAlert(ctx context.Context, cid CID) (*Alert, error) {
+	return pointerTo(NewResource[Alert](c, ALERT).Get(ctx, cid))
+}
+
+// Retrieves the alerts matching a filter, fetching them a page at a time.
+// Every criteria of the filter is sent to Circonus as a search, so that only
+// matching alerts are fetched; they are matched again as they arrive, in
+// case Circonus ignores any part of the search.
+func (c *Client) ListAlerts(ctx context.Context, filter AlertFilter) ([]Alert, error) {
+	if filter.Active && filter.Cleared {
+		return nil, ValidationError{
+			Resource: string(ALERT),
+			Problems: []string{"alerts cannot be both active and cleared"},
+		}
+	}
+
+	query := NewQuery().Tag(filter.Tags...)
+	if filter.Active {
+		query.Search("(active:1)")
+	}
+	if filter.Cleared {
+		query.Search("(active:0)")
+	}
+	if len(filter.Severities) > 0 {
+		severities := make([]string, len(filter.Severities))
+		for i, severity := range filter.Severities {
+			severities[i] = strconv.Itoa(severity)
+		}
+		query.Search("(severity:" + strings.Join(severities, ",") + ")")
+	}
+
+	alerts := []Alert{}
+	it := NewResource[Alert](c, ALERT).Iter(ctx, &ListOptions{Query: query})
+	for it.Next() {
+		if filter.Matches(it.Item()) {
+			alerts = append(alerts, it.Item())
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// Acknowledgement API =================================================== //
+
+// Acknowledges an alert, silencing its notifications for the given
+// duration, and returns the acknowledgement created.
+func (c *Client) Acknowledge(ctx context.Context, alertCID CID, duration time.Duration, notes string) (*Acknowledgement, error) {
+	cid, err := ParseCID(ALERT, string(alertCID))
+	if err != nil {
+		return nil, err
+	}
+	if duration <= 0 {
+		return nil, ValidationError{
+			Resource: string(ACKNOWLEDGEMENT),
+			Problems: []string{"duration must be positive"},
+		}
+	}
+
+	return pointerTo(NewResource[Acknowledgement](c, ACKNOWLEDGEMENT).Create(ctx, Acknowledgement{
+		AcknowledgedUntil: time.Now().Add(duration).Unix(),
+		AlertCID:          cid,
+		Notes:             notes,
+	}))
+}
+
+// Ends the acknowledgement of an alert, if it has one, so that its
+// notifications resume.  Returns the updated acknowledgement, or nil if the
+// alert was not acknowledged.
+func (c *Client) Unacknowledge(ctx context.Context, alertCID CID) (*Acknowledgement, error) {
+	alert, err := c.GetAlert(ctx, alertCID)
+	if err != nil {
+		return nil, err
+	}
+	if alert.AcknowledgementCID == nil {
+		return nil, nil
+	}
+
+	ack, err := c.GetAcknowledgement(ctx, *alert.AcknowledgementCID)
+	if err != nil {
+		return nil, err
+	}
+	ack.AcknowledgedUntil = time.Now().Unix()
+	return c.UpdateAcknowledgement(ctx, ack)
+}
+
+// Retrieves the acknowledgement with the given CID (eg.
+// "/acknowledgement/1234").
+func (c *Client) GetAcknowledgement(ctx context.Context, cid CID) (*Acknowledgement, error) {
+	return pointerTo(NewResource[Acknowledgement](c, ACKNOWLEDGEMENT).Get(ctx, cid))
+}
+
+// Replaces an existing acknowledgement, identified by its CID, and returns
+// the updated acknowledgement.
+func (c *Client) UpdateAcknowledgement(ctx context.Context, ack *Acknowledgement) (*Acknowledgement, error) {
+	if ack.CID == "" {
+		return nil, RequestDataError{Reason: "acknowledgement has no CID"}
+	}
+	return pointerTo(NewResource[Acknowledgement](c, ACKNOWLEDGEMENT).Update(ctx, ack.CID, *ack))
+}
+
+// Retrieves every acknowledgement visible to the Client's access token,
+// optionally filtered and paged by the given options.
+func (c *Client) ListAcknowledgements(ctx context.Context, opts *ListOptions) ([]Acknowledgement, error) {
+	return NewResource[Acknowledgement](c, ACKNOWLEDGEMENT).List(ctx, opts)
+}
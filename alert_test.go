@@ -0,0 +1,221 @@
+package circonus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+
+// Alert Server ========================================================== //
+
+
+/*
+ * An API server holding 250 alerts, paged by the "size" and "from"
+ * querystring parameters and filtered by the "(active:...)" and
+ * "(severity:...)" terms of the "search" parameter.  Alerts cycle through
+ * severities one to five, and every third alert has cleared.  Alert 1 has
+ * been acknowledged, by acknowledgement 7.
+ */
+type alertServer struct {
+	*httptest.Server
+	mutex		sync.Mutex
+	pages		int
+	searches	[]string
+	acks		[]Acknowledgement
+}
+
+
+func createAlert(i int) Alert {
+	alert := Alert {
+		CID:					CID("/alert/" + strconv.Itoa(i)),
+		OccurredOn:		1500000000,
+		Severity:			i % 5 + 1,
+		Tags:					[]string{},
+	}
+	if i % 3 == 0 {
+		cleared := int64(1500000600)
+		alert.ClearedOn = &cleared
+	}
+	if i == 1 {
+		ack := CID("/acknowledgement/7")
+		alert.AcknowledgementCID = &ack
+	}
+	return alert
+}
+
+
+func createAlertServer() *alertServer {
+	server := &alertServer {}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/alert", func(res http.ResponseWriter, req *http.Request) {
+		search := req.URL.Query().Get("search")
+		server.mutex.Lock()
+		server.pages++
+		server.searches = append(server.searches, search)
+		server.mutex.Unlock()
+
+		matching := []Alert{}
+		for i := 0; i < 250; i++ {
+			alert := createAlert(i)
+			active := "(active:0)"
+			if alert.Active() {
+				active = "(active:1)"
+			}
+			if strings.Contains(search, "(active:") && !strings.Contains(search, active) {
+				continue
+			}
+			if severities := severitySearch(search); severities != nil && !contains(severities, strconv.Itoa(alert.Severity)) {
+				continue
+			}
+			matching = append(matching, alert)
+		}
+
+		size, _ := strconv.Atoi(req.URL.Query().Get("size"))
+		from, _ := strconv.Atoi(req.URL.Query().Get("from"))
+		page := []Alert{}
+		for i := from; i < from + size && i < len(matching); i++ {
+			page = append(page, matching[i])
+		}
+		encoded, _ := json.Marshal(page)
+		respond(res, http.StatusOK, string(encoded))
+	})
+	mux.HandleFunc("/alert/", func(res http.ResponseWriter, req *http.Request) {
+		id, _ := strconv.Atoi(req.URL.Path[len("/alert/"):])
+		encoded, _ := json.Marshal(createAlert(id))
+		respond(res, http.StatusOK, string(encoded))
+	})
+	mux.HandleFunc("/acknowledgement", func(res http.ResponseWriter, req *http.Request) {
+		server.record(req)
+		respond(res, http.StatusOK, `{ "_cid":"/acknowledgement/8" }`)
+	})
+	mux.HandleFunc("/acknowledgement/", func(res http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPut {
+			server.record(req)
+		}
+		respond(res, http.StatusOK, `{ "_cid":"/acknowledgement/7", "alert":"/alert/1", "acknowledged_until":1500003600 }`)
+	})
+
+	server.Server = httptest.NewServer(http.StripPrefix("/" + supported_version, mux))
+	return server
+}
+
+
+/*
+ * Returns the severities listed by the "(severity:...)" term of a search, or
+ * nil if it has none.
+ */
+func severitySearch(search string) []string {
+	start := strings.Index(search, "(severity:")
+	if start < 0 {
+		return nil
+	}
+	list := search[start + len("(severity:"):]
+	return strings.Split(list[:strings.Index(list, ")")], ",")
+}
+
+
+/*
+ * Records an acknowledgement sent to the server.
+ */
+func (s *alertServer) record(req *http.Request) {
+	var ack Acknowledgement
+	json.NewDecoder(req.Body).Decode(&ack)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.acks = append(s.acks, ack)
+}
+
+
+// Tests ================================================================= //
+
+
+func TestListAlerts(t *testing.T) {
+	server := createAlertServer()
+	defer server.Close()
+	client := createClient(server.Server)
+	ctx := context.Background()
+
+	alerts, err := client.ListAlerts(ctx, AlertFilter {})
+	if err != nil {
+		t.Fatalf("ListAlerts failed unexpectedly: %s\n", err.Error())
+	}
+	expect(t, len(alerts), 250)
+	expect(t, server.pages, 3)
+
+	alerts, err = client.ListAlerts(ctx, AlertFilter { Active:true, Severities:[]int{ 1 } })
+	if err != nil {
+		t.Fatalf("ListAlerts failed unexpectedly: %s\n", err.Error())
+	}
+	expect(t, len(alerts), 33)
+	for _, alert := range alerts {
+		if !alert.Active() || alert.Severity != 1 {
+			t.Errorf("Alert %s does not match the filter\n", alert.CID)
+		}
+	}
+
+	// Filters are applied by the server, rather than by paging through every
+	// alert
+	expect(t, server.pages, 4)
+	expect(t, server.searches[3], "(active:1) (severity:1)")
+
+	alerts, _ = client.ListAlerts(ctx, AlertFilter { Cleared:true, Severities:[]int{ 2, 3 } })
+	expect(t, len(alerts), 33)
+	expect(t, server.pages, 5)
+	expect(t, server.searches[4], "(active:0) (severity:2,3)")
+
+	alerts, _ = client.ListAlerts(ctx, AlertFilter { Cleared:true })
+	expect(t, len(alerts), 84)
+
+	_, err = client.ListAlerts(ctx, AlertFilter { Active:true, Cleared:true })
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected a ValidationError, got %v\n", err)
+	}
+}
+
+
+func TestAcknowledge(t *testing.T) {
+	server := createAlertServer()
+	defer server.Close()
+	client := createClient(server.Server)
+	ctx := context.Background()
+
+	before := time.Now().Unix()
+	ack, err := client.Acknowledge(ctx, "2", time.Hour, "investigating")
+	if err != nil {
+		t.Fatalf("Acknowledge failed unexpectedly: %s\n", err.Error())
+	}
+	expect(t, ack.CID, CID("/acknowledgement/8"))
+	expect(t, server.acks[0].AlertCID, CID("/alert/2"))
+	expect(t, server.acks[0].Notes, "investigating")
+	expect(t, server.acks[0].AcknowledgedUntil - before >= 3600, true)
+
+	ack, err = client.Unacknowledge(ctx, "/alert/1")
+	if err != nil {
+		t.Fatalf("Unacknowledge failed unexpectedly: %s\n", err.Error())
+	}
+	expect(t, ack.CID, CID("/acknowledgement/7"))
+	expect(t, server.acks[1].AlertCID, CID("/alert/1"))
+	expect(t, server.acks[1].AcknowledgedUntil >= before, true)
+	expect(t, server.acks[1].AcknowledgedUntil <= time.Now().Unix(), true)
+
+	// Alerts without acknowledgements are left alone
+	ack, err = client.Unacknowledge(ctx, "/alert/2")
+	if err != nil || ack != nil {
+		t.Errorf("Expected no acknowledgement and no error, got %v, %v\n", ack, err)
+	}
+	expect(t, len(server.acks), 2)
+
+	if _, err := client.Acknowledge(ctx, "/check/2", time.Hour, ""); !errors.Is(err, ErrInvalidCID) {
+		t.Errorf("Expected an InvalidCIDError, got %v\n", err)
+	}
+}
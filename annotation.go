@@ -0,0 +1,102 @@
+package circonus
+
+import (
+	"context"
+	"time"
+)
+
+// Structures ============================================================ //
+
+// An Annotation marks an event, such as a deploy, on graphs covering the
+// time it occurred.
+//
+// Start and Stop are given in seconds since the epoch.  Annotations may be
+// related to specific metrics, each identified in the form
+// "<check id>_<metric name>" (eg. "1234_requests").
+type Annotation struct {
+	CID            CID      `json:"_cid,omitempty"`
+	Created        int64    `json:"_created,omitempty"`
+	LastModified   int64    `json:"_last_modified,omitempty"`
+	LastModifiedBy string   `json:"_last_modified_by,omitempty"`
+	Category       string   `json:"category"`
+	Description    string   `json:"description"`
+	RelatedMetrics []string `json:"rel_metrics"`
+	Start          int64    `json:"start"`
+	Stop           int64    `json:"stop"`
+	Title          string   `json:"title"`
+}
+
+// Annotation API ======================================================== //
+
+// Creates a new annotation and returns it as stored by Circonus.
+func (c *Client) CreateAnnotation(ctx context.Context, a *Annotation) (*Annotation, error) {
+	return pointerTo(NewResource[Annotation](c, ANNOTATION).Create(ctx, *a))
+}
+
+// Retrieves the annotation with the given CID (eg. "/annotation/1234").
+func (c *Client) GetAnnotation(ctx context.Context, cid CID) (*Annotation, error) {
+	return pointerTo(NewResource[Annotation](c, ANNOTATION).Get(ctx, cid))
+}
+
+// Replaces an existing annotation, identified by its CID, and returns the
+// updated annotation.
+func (c *Client) UpdateAnnotation(ctx context.Context, a *Annotation) (*Annotation, error) {
+	if a.CID == "" {
+		return nil, RequestDataError{Reason: "annotation has no CID"}
+	}
+	return pointerTo(NewResource[Annotation](c, ANNOTATION).Update(ctx, a.CID, *a))
+}
+
+// Deletes the annotation with the given CID.
+func (c *Client) DeleteAnnotation(ctx context.Context, cid CID) error {
+	return NewResource[Annotation](c, ANNOTATION).Delete(ctx, cid)
+}
+
+// Retrieves every annotation visible to the Client's access token,
+// optionally filtered and paged by the given options.
+func (c *Client) ListAnnotations(ctx context.Context, opts *ListOptions) ([]Annotation, error) {
+	return NewResource[Annotation](c, ANNOTATION).List(ctx, opts)
+}
+
+// Annotates an event beginning now and lasting for the given duration,
+// which may be zero but not negative, and returns the CID of the created
+// annotation.  The event may be related to any number of metrics, as for
+// Annotation.
+func (c *Client) MarkEvent(ctx context.Context, category string, title string, duration time.Duration, metrics ...string) (CID, error) {
+	if duration < 0 {
+		return "", ValidationError{Resource: string(ANNOTATION), Problems: []string{"duration cannot be negative"}}
+	}
+
+	start := time.Now()
+	a := &Annotation{
+		Category:       category,
+		RelatedMetrics: append([]string{}, metrics...),
+		Start:          start.Unix(),
+		Stop:           start.Add(duration).Unix(),
+		Title:          title,
+	}
+
+	created, err := c.CreateAnnotation(ctx, a)
+	if err != nil {
+		return "", err
+	}
+	return created.CID, nil
+}
+
+// Checks that an annotation has a title, and does not stop before it
+// starts.
+func (a Annotation) Validate() error {
+	var problems []string
+
+	if a.Title == "" {
+		problems = append(problems, "no title specified")
+	}
+	if a.Stop < a.Start {
+		problems = append(problems, "stop cannot be before start")
+	}
+
+	if len(problems) > 0 {
+		return ValidationError{Resource: string(ANNOTATION), Problems: problems}
+	}
+	return nil
+}
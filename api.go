@@ -1,47 +1,109 @@
 package circonus
 
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
 
 func (c *Client) Add(resource string, data interface{}, params map[string]string) (interface{}, error) {
+	return c.AddContext(context.Background(), resource, data, params)
+}
+
+func (c *Client) Delete(resource string, id string, data interface{}) (interface{}, error) {
+	return c.DeleteContext(context.Background(), resource, id, data)
+}
+
+func (c *Client) Edit(resource string, id string, data interface{}) (interface{}, error) {
+	return c.EditContext(context.Background(), resource, id, data)
+}
+
+func (c *Client) Get(resource string, id string, data interface{}) (interface{}, error) {
+	return c.GetContext(context.Background(), resource, id, data)
+}
+
+func (c *Client) List(resource string, opts *ListOptions) (interface{}, error) {
+	return c.ListContext(context.Background(), resource, opts)
+}
+
+func (c *Client) AddContext(ctx context.Context, resource string, data interface{}, params map[string]string) (interface{}, error) {
+	query := make(url.Values)
+	for key, value := range params {
+		query.Set(key, value)
+	}
+
 	req := request{
-		Method:     "POST",
-		Resource:   resource,
+		Method:     http.MethodPost,
+		Resource:   collectionPath(resource),
 		Data:       data,
-		Parameters: params,
+		Parameters: query,
 	}
-	return c.send(req)
+	return c.send(ctx, req)
 }
 
-func (c *Client) Delete(resource string, id string, data interface{}) (interface{}, error) {
+// The data argument is ignored, as Circonus does not accept request bodies
+// on deletions.
+func (c *Client) DeleteContext(ctx context.Context, resource string, id string, data interface{}) (interface{}, error) {
+	path, err := itemPath(resource, id)
+	if err != nil {
+		return nil, err
+	}
+
 	req := request{
-		Method:     "DELETE",
-		Resource:   resource + "/" + id,
-		Data:       data,
+		Method:     http.MethodDelete,
+		Resource:   path,
 	}
-	return c.send(req)
+	return c.send(ctx, req)
 }
 
-func (c *Client) Edit(resource string, id string, data interface{}) (interface{}, error) {
+func (c *Client) EditContext(ctx context.Context, resource string, id string, data interface{}) (interface{}, error) {
+	path, err := itemPath(resource, id)
+	if err != nil {
+		return nil, err
+	}
+
 	req := request{
-		Method:     "PUT",
-		Resource:   resource + "/" + id,
+		Method:     http.MethodPut,
+		Resource:   path,
 		Data:       data,
 	}
-	return c.send(req)
+	return c.send(ctx, req)
 }
 
-func (c *Client) Get(resource string, id string, data interface{}) (interface{}, error) {
+// The data argument is ignored, as Circonus does not accept request bodies
+// on retrievals.
+func (c *Client) GetContext(ctx context.Context, resource string, id string, data interface{}) (interface{}, error) {
+	path, err := itemPath(resource, id)
+	if err != nil {
+		return nil, err
+	}
+
 	req := request{
-		Method:     "Get",
-		Resource:   resource + "/" + id,
-		Data:       data,
+		Method:     http.MethodGet,
+		Resource:   path,
 	}
-	return c.send(req)
+	return c.send(ctx, req)
 }
 
-func (c *Client) List(resource string, data interface{}) (interface{}, error) {
+func (c *Client) ListContext(ctx context.Context, resource string, opts *ListOptions) (interface{}, error) {
 	req := request{
-		Method:     "GET",
-		Resource:   resource,
+		Method:     http.MethodGet,
+		Resource:   collectionPath(resource),
+		Parameters: opts.parameters(),
 	}
-	return c.send(req)
+	return c.send(ctx, req)
+}
+
+// Returns the request path of a resource given by name, with or without a
+// leading slash (eg. "check_bundle" or "/check_bundle").
+func collectionPath(name string) string {
+	return resource(strings.Trim(name, "/")).path()
+}
+
+// Returns the request path of an item of a resource given by name, where the
+// item is identified by either a bare ID or a full CID.
+func itemPath(name string, id string) (string, error) {
+	return resource(strings.Trim(name, "/")).item(CID(id))
 }
@@ -0,0 +1,387 @@
+package circonus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+
+// Recording Server ====================================================== //
+
+
+/*
+ * A request received by the recording server.
+ */
+type recorded struct {
+	Method	string
+	Path		string
+	Query		string
+	Body		string
+	Header	http.Header
+}
+
+
+/*
+ * Creates an HTTP server which records every request it receives, for
+ * checking what the Client sends.
+ *
+ * Retrievals of a collection (eg. "/v2/graph") are answered with an empty
+ * list, deletions with no content, and all other requests with an empty
+ * object.
+ */
+func createRecordingServer(requests chan recorded) *httptest.Server {
+	handler := func(res http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		requests <- recorded {
+			Method:		req.Method,
+			Path:			req.URL.Path,
+			Query:		req.URL.RawQuery,
+			Body:			string(body),
+			Header:		req.Header,
+		}
+
+		segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+		switch {
+		case req.Method == http.MethodDelete:
+			res.WriteHeader(http.StatusNoContent)
+		case req.Method == http.MethodGet && len(segments) == 2:
+			respond(res, http.StatusOK, "[]")
+		default:
+			respond(res, http.StatusOK, "{}")
+		}
+	}
+	return httptest.NewServer(http.HandlerFunc(handler))
+}
+
+
+/*
+ * Returns the JSON encoding of a value, as the Client would send it.
+ */
+func encode(t *testing.T, v interface{}) string {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Cannot encode %v: %s\n", v, err.Error())
+	}
+	return string(encoded)
+}
+
+
+// Tests ================================================================= //
+
+
+func TestRequests(t *testing.T) {
+	requests := make(chan recorded, 1)
+	client := createClient(createRecordingServer(requests))
+	ctx := context.Background()
+
+	bundle := &CheckBundle{ CID:"/check_bundle/1234", DisplayName:"bundle" }
+	graph := &Graph{ CID:"/graph/7a0b9ae6-1d4c-4b3e-8f10-2e5f5c1d2a3b", Title:"graph" }
+	group := NewContactGroup("group")
+	group.CID = "/contact_group/12"
+	ruleset, _ := NewRuleSetBuilder("/check/1", "cpu", METRIC_NUMERIC).MaxValue(1, 90).Build()
+	ruleset.CID = "/rule_set/1_cpu"
+	annotation := &Annotation{ CID:"/annotation/5", Title:"deploy", Start:1500000000, Stop:1500000000 }
+	maintenance := &Maintenance{ CID:"/maintenance/8", Item:"/check/1", Severities:MaintenanceSeverities{ 1, 2 }, Start:1500000000, Stop:1500003600, Type:MAINTENANCE_CHECK }
+	data := map[string]string{ "name":"value" }
+
+	tests := []struct {
+		name		string
+		call		func() error
+		method	string
+		path		string
+		body		string
+	}{
+		{ "Add", func() error {
+			_, err := client.Add("check_bundle", data, nil); return err
+		}, "POST", "/v2/check_bundle", encode(t, data) },
+		{ "Get", func() error {
+			_, err := client.Get("check_bundle", "1234", data); return err
+		}, "GET", "/v2/check_bundle/1234", "" },
+		{ "Get (CID)", func() error {
+			_, err := client.Get("check_bundle", "/check_bundle/1234", nil); return err
+		}, "GET", "/v2/check_bundle/1234", "" },
+		{ "Edit", func() error {
+			_, err := client.Edit("/check_bundle", "1234", data); return err
+		}, "PUT", "/v2/check_bundle/1234", encode(t, data) },
+		{ "Delete", func() error {
+			_, err := client.Delete("check_bundle", "1234", data); return err
+		}, "DELETE", "/v2/check_bundle/1234", "" },
+		{ "List", func() error {
+			_, err := client.List("check_bundle", nil); return err
+		}, "GET", "/v2/check_bundle", "" },
+
+		{ "CreateCheckBundle", func() error {
+			_, err := client.CreateCheckBundle(ctx, bundle); return err
+		}, "POST", "/v2/check_bundle", encode(t, bundle) },
+		{ "GetCheckBundle", func() error {
+			_, err := client.GetCheckBundle(ctx, bundle.CID); return err
+		}, "GET", "/v2/check_bundle/1234", "" },
+		{ "UpdateCheckBundle", func() error {
+			_, err := client.UpdateCheckBundle(ctx, bundle); return err
+		}, "PUT", "/v2/check_bundle/1234", encode(t, bundle) },
+		{ "DeleteCheckBundle", func() error {
+			return client.DeleteCheckBundle(ctx, "1234")
+		}, "DELETE", "/v2/check_bundle/1234", "" },
+		{ "ListCheckBundles", func() error {
+			_, err := client.ListCheckBundles(ctx, nil); return err
+		}, "GET", "/v2/check_bundle", "" },
+
+		{ "CreateGraph", func() error {
+			_, err := client.CreateGraph(ctx, graph); return err
+		}, "POST", "/v2/graph", encode(t, graph) },
+		{ "GetGraph", func() error {
+			_, err := client.GetGraph(ctx, graph.CID); return err
+		}, "GET", "/v2" + string(graph.CID), "" },
+		{ "UpdateGraph", func() error {
+			_, err := client.UpdateGraph(ctx, graph); return err
+		}, "PUT", "/v2" + string(graph.CID), encode(t, graph) },
+		{ "DeleteGraph", func() error {
+			return client.DeleteGraph(ctx, graph.CID)
+		}, "DELETE", "/v2" + string(graph.CID), "" },
+		{ "ListGraphs", func() error {
+			_, err := client.ListGraphs(ctx, nil); return err
+		}, "GET", "/v2/graph", "" },
+
+		{ "CreateRuleSet", func() error {
+			_, err := client.CreateRuleSet(ctx, ruleset); return err
+		}, "POST", "/v2/rule_set", encode(t, ruleset) },
+		{ "GetRuleSet", func() error {
+			_, err := client.GetRuleSet(ctx, ruleset.CID); return err
+		}, "GET", "/v2/rule_set/1_cpu", "" },
+		{ "UpdateRuleSet", func() error {
+			_, err := client.UpdateRuleSet(ctx, ruleset); return err
+		}, "PUT", "/v2/rule_set/1_cpu", encode(t, ruleset) },
+		{ "DeleteRuleSet", func() error {
+			return client.DeleteRuleSet(ctx, ruleset.CID)
+		}, "DELETE", "/v2/rule_set/1_cpu", "" },
+		{ "ListRuleSets", func() error {
+			_, err := client.ListRuleSets(ctx, nil); return err
+		}, "GET", "/v2/rule_set", "" },
+
+		{ "CreateContactGroup", func() error {
+			_, err := client.CreateContactGroup(ctx, group); return err
+		}, "POST", "/v2/contact_group", encode(t, group) },
+		{ "GetContactGroup", func() error {
+			_, err := client.GetContactGroup(ctx, group.CID); return err
+		}, "GET", "/v2/contact_group/12", "" },
+		{ "UpdateContactGroup", func() error {
+			_, err := client.UpdateContactGroup(ctx, group); return err
+		}, "PUT", "/v2/contact_group/12", encode(t, group) },
+		{ "DeleteContactGroup", func() error {
+			return client.DeleteContactGroup(ctx, group.CID)
+		}, "DELETE", "/v2/contact_group/12", "" },
+		{ "ListContactGroups", func() error {
+			_, err := client.ListContactGroups(ctx, nil); return err
+		}, "GET", "/v2/contact_group", "" },
+
+		{ "CreateAnnotation", func() error {
+			_, err := client.CreateAnnotation(ctx, annotation); return err
+		}, "POST", "/v2/annotation", encode(t, annotation) },
+		{ "GetAnnotation", func() error {
+			_, err := client.GetAnnotation(ctx, annotation.CID); return err
+		}, "GET", "/v2/annotation/5", "" },
+		{ "UpdateAnnotation", func() error {
+			_, err := client.UpdateAnnotation(ctx, annotation); return err
+		}, "PUT", "/v2/annotation/5", encode(t, annotation) },
+		{ "DeleteAnnotation", func() error {
+			return client.DeleteAnnotation(ctx, "5")
+		}, "DELETE", "/v2/annotation/5", "" },
+		{ "ListAnnotations", func() error {
+			_, err := client.ListAnnotations(ctx, nil); return err
+		}, "GET", "/v2/annotation", "" },
+
+		{ "CreateMaintenance", func() error {
+			_, err := client.CreateMaintenance(ctx, maintenance); return err
+		}, "POST", "/v2/maintenance", encode(t, maintenance) },
+		{ "GetMaintenance", func() error {
+			_, err := client.GetMaintenance(ctx, maintenance.CID); return err
+		}, "GET", "/v2/maintenance/8", "" },
+		{ "UpdateMaintenance", func() error {
+			_, err := client.UpdateMaintenance(ctx, maintenance); return err
+		}, "PUT", "/v2/maintenance/8", encode(t, maintenance) },
+		{ "DeleteMaintenance", func() error {
+			return client.DeleteMaintenance(ctx, "8")
+		}, "DELETE", "/v2/maintenance/8", "" },
+		{ "ListMaintenance", func() error {
+			_, err := client.ListMaintenance(ctx, nil); return err
+		}, "GET", "/v2/maintenance", "" },
+
+		{ "GetBroker", func() error {
+			_, err := client.GetBroker(ctx, "/broker/1"); return err
+		}, "GET", "/v2/broker/1", "" },
+		{ "ListBrokers", func() error {
+			_, err := client.ListBrokers(ctx, nil); return err
+		}, "GET", "/v2/broker", "" },
+		{ "FindBrokers", func() error {
+			_, err := client.FindBrokers(ctx, BrokerFilter{ CheckType:CHECK_TYPE_HTTPTRAP }); return err
+		}, "GET", "/v2/broker", "" },
+		{ "GetCheck", func() error {
+			_, err := client.GetCheck(ctx, "1"); return err
+		}, "GET", "/v2/check/1", "" },
+		{ "ListChecks", func() error {
+			_, err := client.ListChecks(ctx, nil); return err
+		}, "GET", "/v2/check", "" },
+	}
+
+	for _, test := range tests {
+		if err := test.call(); err != nil {
+			t.Errorf("%s: failed unexpectedly: %s\n", test.name, err.Error())
+			continue
+		}
+
+		req := <- requests
+		expect(t, req.Method, test.method)
+		expect(t, req.Path, test.path)
+		expect(t, strings.TrimSpace(req.Body), test.body)
+		expect(t, req.Header.Get("Accept"), "application/json")
+		expect(t, req.Header.Get("Content-Type"), "application/json")
+		expect(t, req.Header.Get("X-Circonus-App-Name"), "sampleapp")
+		expect(t, req.Header.Get("X-Circonus-Auth-Token"), "abc123")
+	}
+}
+
+
+func TestMarkEvent(t *testing.T) {
+	requests := make(chan recorded, 1)
+	client := createClient(createRecordingServer(requests))
+
+	before := time.Now().Unix()
+	_, err := client.MarkEvent(context.Background(), "deploys", "api v1.2.3", time.Duration(90) * time.Second, "1234_requests")
+	if err != nil {
+		t.Fatalf("MarkEvent failed unexpectedly: %s\n", err.Error())
+	}
+
+	req := <- requests
+	expect(t, req.Method, "POST")
+	expect(t, req.Path, "/v2/annotation")
+
+	var a Annotation
+	json.Unmarshal([]byte(req.Body), &a)
+	expect(t, a.Category, "deploys")
+	expect(t, a.Title, "api v1.2.3")
+	expect(t, a.Stop - a.Start, int64(90))
+	expect(t, a.Start >= before && a.Start <= time.Now().Unix(), true)
+	expect(t, len(a.RelatedMetrics), 1)
+	expect(t, a.RelatedMetrics[0], "1234_requests")
+
+	// Invalid annotations are not sent
+	ctx := context.Background()
+	if _, err := client.MarkEvent(ctx, "deploys", "api v1.2.3", -time.Minute); !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected a ValidationError for a negative duration, got %v\n", err)
+	}
+	if _, err := client.MarkEvent(ctx, "deploys", "api v1.2.3", time.Duration(-300) * time.Millisecond); !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected a ValidationError for a negative sub-second duration, got %v\n", err)
+	}
+	untitled := &Annotation{ CID:"/annotation/5", Start:1500000000, Stop:1500000060 }
+	if _, err := client.CreateAnnotation(ctx, untitled); !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected a ValidationError for an untitled annotation, got %v\n", err)
+	}
+	reversed := &Annotation{ CID:"/annotation/5", Title:"deploy", Start:1500000060, Stop:1500000000 }
+	_, err = client.UpdateAnnotation(ctx, reversed)
+	var verr ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Expected a ValidationError for a reversed annotation, got %v\n", err)
+	}
+	expect(t, verr.Problems[0], "stop cannot be before start")
+	select {
+	case req := <- requests:
+		t.Errorf("Invalid annotation was sent to %s\n", req.Path)
+	default:
+	}
+}
+
+
+func TestMaintenance(t *testing.T) {
+	requests := make(chan recorded, 1)
+	client := createClient(createRecordingServer(requests))
+	ctx := context.Background()
+
+	_, err := client.SilenceCheckFor(ctx, "1234", time.Hour)
+	if err != nil {
+		t.Fatalf("SilenceCheckFor failed unexpectedly: %s\n", err.Error())
+	}
+
+	req := <- requests
+	expect(t, req.Path, "/v2/maintenance")
+
+	var m Maintenance
+	if err := json.Unmarshal([]byte(req.Body), &m); err != nil {
+		t.Fatalf("Cannot decode maintenance: %s\n", err.Error())
+	}
+	expect(t, m.Type, MAINTENANCE_CHECK)
+	expect(t, m.Item, "/check/1234")
+	expect(t, m.Stop - m.Start, int64(3600))
+	expect(t, len(m.Severities), 5)
+	expect(t, strings.Contains(req.Body, `"severities":["1","2","3","4","5"]`), true)
+
+	// Invalid windows are not sent
+	invalid := []*Maintenance {
+		{ Item:"/check/1", Severities:MaintenanceSeverities{ 1 }, Start:1500003600, Stop:1500000000, Type:MAINTENANCE_CHECK },
+		{ Item:"/rule_set/1_cpu", Severities:MaintenanceSeverities{ 1 }, Start:1, Stop:2, Type:MAINTENANCE_CHECK },
+		{ Item:"db1", Severities:MaintenanceSeverities{ 6 }, Start:1, Stop:2, Type:MAINTENANCE_HOST },
+		{ Item:"db1", Severities:MaintenanceSeverities{ 1 }, Start:1, Stop:2, Type:"server" },
+	}
+	for _, m := range invalid {
+		if _, err := client.CreateMaintenance(ctx, m); !errors.Is(err, ErrValidation) {
+			t.Errorf("Expected a ValidationError for %+v, got %v\n", *m, err)
+		}
+	}
+	if _, err := client.SilenceCheckFor(ctx, "1234", -time.Hour); !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected a ValidationError for a negative duration, got %v\n", err)
+	}
+	select {
+	case req := <- requests:
+		t.Errorf("Invalid maintenance window was sent to %s\n", req.Path)
+	default:
+	}
+}
+
+
+func TestUnsupportedMethods(t *testing.T) {
+	requests := make(chan recorded, 1)
+	client := createClient(createRecordingServer(requests))
+
+	tests := []struct {
+		name		string
+		call		func() error
+	}{
+		{ "Add account", func() error {
+			_, err := client.Add("account", map[string]string{}, nil); return err
+		} },
+		{ "Edit broker", func() error {
+			_, err := client.Edit("broker", "1", map[string]string{}); return err
+		} },
+		{ "Delete user", func() error {
+			_, err := client.Delete("user", "1", nil); return err
+		} },
+		{ "Edit check", func() error {
+			_, err := client.Edit("check", "1", map[string]string{}); return err
+		} },
+		{ "Edit alert", func() error {
+			_, err := client.Edit("alert", "1", map[string]string{}); return err
+		} },
+		{ "Delete acknowledgement", func() error {
+			_, err := client.Delete("acknowledgement", "1", nil); return err
+		} },
+	}
+
+	for _, test := range tests {
+		err := test.call()
+		if !errors.Is(err, ErrUnsupportedMethod) {
+			t.Errorf("%s: expected an UnsupportedMethodError, got %v\n", test.name, err)
+		}
+	}
+
+	select {
+	case req := <- requests:
+		t.Errorf("Unsupported %s request to %s was sent\n", req.Method, req.Path)
+	default:
+	}
+}
@@ -0,0 +1,165 @@
+package circonus
+
+import (
+	"context"
+	"sort"
+	"strconv"
+)
+
+// Structures ============================================================ //
+
+// A Broker collects metrics on behalf of checks.  Brokers may be run by
+// Circonus ("circonus") or within a customer's own infrastructure
+// ("enterprise"), and consist of one or more instances.
+type Broker struct {
+	CID       CID            `json:"_cid"`
+	Details   []BrokerDetail `json:"_details"`
+	Latitude  *string        `json:"_latitude"`
+	Longitude *string        `json:"_longitude"`
+	Name      string         `json:"_name"`
+	Tags      []string       `json:"_tags"`
+	Type      string         `json:"_type"`
+}
+
+// A BrokerDetail describes a single instance of a Broker.
+type BrokerDetail struct {
+	CN           string   `json:"cn"`
+	ExternalHost *string  `json:"external_host"`
+	ExternalPort int      `json:"external_port"`
+	IP           *string  `json:"ipaddress"`
+	MinVersion   int      `json:"minimum_version_required"`
+	Modules      []string `json:"modules"` // Check types supported
+	Port         *int     `json:"port"`
+	Skew         *string  `json:"skew"`
+	Status       string   `json:"status"`
+	Version      *int     `json:"version"`
+}
+
+// A Check is the instance of a CheckBundle running on a single Broker.
+// Checks are created and removed along with their bundles.
+type Check struct {
+	CID            CID               `json:"_cid"`
+	Active         bool              `json:"_active"`
+	BrokerCID      CID               `json:"_broker"`
+	CheckBundleCID CID               `json:"_check_bundle"`
+	CheckUUID      string            `json:"_check_uuid"`
+	Details        map[string]string `json:"_details"`
+}
+
+// A BrokerFilter selects brokers suitable for running a check.
+type BrokerFilter struct {
+	CheckType string   // Check type the broker must support (eg. "httptrap")
+	Tags      []string // Tags the broker must have all of (eg. "region:us-east")
+	Type      string   // Type of broker, "circonus" or "enterprise"
+}
+
+// Constants & Data ====================================================== //
+
+// Commonly used check types.
+const (
+	CHECK_TYPE_HTTPTRAP  string = "httptrap"
+	CHECK_TYPE_JSON      string = "json"
+	CHECK_TYPE_PING_ICMP string = "ping_icmp"
+)
+
+const broker_status_active string = "active"
+
+// Broker Helpers ======================================================== //
+
+// Reports whether any instance of a Broker is active.
+func (b Broker) Active() bool {
+	for _, detail := range b.Details {
+		if detail.Status == broker_status_active {
+			return true
+		}
+	}
+	return false
+}
+
+// Reports whether an active instance of a Broker supports the given check
+// type.
+func (b Broker) Supports(checkType string) bool {
+	for _, detail := range b.Details {
+		if detail.Status == broker_status_active && contains(detail.Modules, checkType) {
+			return true
+		}
+	}
+	return false
+}
+
+// Reports whether a Broker is active and matches every criteria of a
+// filter.
+func (f BrokerFilter) Matches(b Broker) bool {
+	if !b.Active() {
+		return false
+	}
+	if f.CheckType != "" && !b.Supports(f.CheckType) {
+		return false
+	}
+	if f.Type != "" && b.Type != f.Type {
+		return false
+	}
+	for _, tag := range f.Tags {
+		if !contains(b.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// Broker API ============================================================ //
+
+// Returns the active brokers matching a filter, ordered by CID so that the
+// same broker is chosen when picking the first of them.
+func (c *Client) FindBrokers(ctx context.Context, filter BrokerFilter) ([]Broker, error) {
+	brokers, err := c.ListBrokers(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []Broker{}
+	for _, broker := range brokers {
+		if filter.Matches(broker) {
+			matches = append(matches, broker)
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return cidLess(matches[i].CID, matches[j].CID)
+	})
+	return matches, nil
+}
+
+// Retrieves the broker with the given CID.
+func (c *Client) GetBroker(ctx context.Context, cid CID) (*Broker, error) {
+	return pointerTo(NewResource[Broker](c, BROKER).Get(ctx, cid))
+}
+
+// Retrieves every broker visible to the Client's access token,
+// optionally filtered and paged by the given options.
+func (c *Client) ListBrokers(ctx context.Context, opts *ListOptions) ([]Broker, error) {
+	return NewResource[Broker](c, BROKER).List(ctx, opts)
+}
+
+// Check API ============================================================= //
+
+// Retrieves the check with the given CID.
+func (c *Client) GetCheck(ctx context.Context, cid CID) (*Check, error) {
+	return pointerTo(NewResource[Check](c, CHECK).Get(ctx, cid))
+}
+
+// Retrieves every check visible to the Client's access token,
+// optionally filtered and paged by the given options.
+func (c *Client) ListChecks(ctx context.Context, opts *ListOptions) ([]Check, error) {
+	return NewResource[Check](c, CHECK).List(ctx, opts)
+}
+
+// Orders CIDs by their numeric ID where possible, and lexically otherwise.
+func cidLess(a CID, b CID) bool {
+	x, xerr := strconv.ParseInt(a.ID(), 10, 64)
+	y, yerr := strconv.ParseInt(b.ID(), 10, 64)
+	if xerr == nil && yerr == nil {
+		return x < y
+	}
+	return a < b
+}
@@ -0,0 +1,91 @@
+package circonus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+
+/*
+ * Brokers of both types, listed out of order, with instances in varying
+ * states:
+ *
+ *   /broker/1    - circonus, us-east, active with httptrap and json.
+ *   /broker/2    - circonus, us-east, inactive, though it supports httptrap.
+ *   /broker/3    - enterprise, us-west, active only with json; its httptrap
+ *                  instance is unprovisioned.
+ *   /broker/10   - enterprise, us-east, active with httptrap.
+ *   /broker/20   - circonus, us-west, active with httptrap and ping_icmp.
+ */
+const brokerFixture = `[
+	{ "_cid":"/broker/20", "_type":"circonus", "_tags":["region:us-west"], "_details":[
+		{ "status":"active", "modules":["httptrap","ping_icmp"] }
+	] },
+	{ "_cid":"/broker/3", "_type":"enterprise", "_tags":["region:us-west"], "_details":[
+		{ "status":"active", "modules":["json"] },
+		{ "status":"unprovisioned", "modules":["httptrap"] }
+	] },
+	{ "_cid":"/broker/10", "_type":"enterprise", "_tags":["region:us-east","tier:1"], "_details":[
+		{ "status":"active", "modules":["httptrap"] }
+	] },
+	{ "_cid":"/broker/2", "_type":"circonus", "_tags":["region:us-east"], "_details":[
+		{ "status":"disconnected", "modules":["httptrap","json"] }
+	] },
+	{ "_cid":"/broker/1", "_type":"circonus", "_tags":["region:us-east","tier:1"], "_details":[
+		{ "status":"disconnected", "modules":["ping_icmp"] },
+		{ "status":"active", "modules":["httptrap","json"] }
+	] }
+]`
+
+
+func TestFindBrokers(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/broker", func(res http.ResponseWriter, req *http.Request) {
+		respond(res, http.StatusOK, brokerFixture)
+	})
+	server := httptest.NewServer(http.StripPrefix("/" + supported_version, mux))
+	defer server.Close()
+	client := createClient(server)
+	ctx := context.Background()
+
+	tests := []struct {
+		name		string
+		filter	BrokerFilter
+		cids		[]CID
+	}{
+		{ "any active", BrokerFilter {},
+			[]CID{ "/broker/1", "/broker/3", "/broker/10", "/broker/20" } },
+		{ "httptrap", BrokerFilter { CheckType:CHECK_TYPE_HTTPTRAP },
+			[]CID{ "/broker/1", "/broker/10", "/broker/20" } },
+		{ "json", BrokerFilter { CheckType:CHECK_TYPE_JSON },
+			[]CID{ "/broker/1", "/broker/3" } },
+		{ "ping on an active instance", BrokerFilter { CheckType:CHECK_TYPE_PING_ICMP },
+			[]CID{ "/broker/20" } },
+		{ "enterprise", BrokerFilter { Type:"enterprise" },
+			[]CID{ "/broker/3", "/broker/10" } },
+		{ "tagged", BrokerFilter { Tags:[]string{ "region:us-east" } },
+			[]CID{ "/broker/1", "/broker/10" } },
+		{ "every criteria", BrokerFilter { CheckType:CHECK_TYPE_HTTPTRAP, Tags:[]string{ "region:us-east", "tier:1" }, Type:"circonus" },
+			[]CID{ "/broker/1" } },
+		{ "none", BrokerFilter { CheckType:CHECK_TYPE_HTTPTRAP, Tags:[]string{ "region:us-west" }, Type:"enterprise" },
+			[]CID{} },
+	}
+
+	for _, test := range tests {
+		brokers, err := client.FindBrokers(ctx, test.filter)
+		if err != nil {
+			t.Fatalf("%s: FindBrokers failed unexpectedly: %s\n", test.name, err.Error())
+		}
+		if len(brokers) != len(test.cids) {
+			t.Errorf("%s: expected %d brokers, got %d\n", test.name, len(test.cids), len(brokers))
+			continue
+		}
+		for i, cid := range test.cids {
+			if brokers[i].CID != cid {
+				t.Errorf("%s: expected broker %d to be %s, got %s\n", test.name, i, cid, brokers[i].CID)
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package circonus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Constants & Data ====================================================== //
+
+// Format in which CAQL results are requested, matching that of FetchData.
+const caql_format string = "DF4"
+
+// CAQL API ============================================================== //
+
+// Runs a CAQL query over the window from start to end, in periods of the
+// given length, returning a series for each output of the query.  Series of
+// histograms are returned with the kind FETCH_KIND_HISTOGRAM.
+//
+// Queries which Circonus cannot run, such as those with syntax errors, are
+// reported with a CAQLError.
+func (c *Client) CAQL(ctx context.Context, query string, start time.Time, end time.Time, period time.Duration) (*FetchResult, error) {
+	var problems []string
+	if query == "" {
+		problems = append(problems, "no query specified")
+	}
+	if !end.After(start) {
+		problems = append(problems, "end must be after start")
+	}
+	if period < time.Second || period%time.Second != 0 {
+		problems = append(problems, "period must be a whole number of seconds")
+	}
+	if len(problems) > 0 {
+		return nil, ValidationError{Resource: string(CAQL), Problems: problems}
+	}
+
+	var response fetchResponse
+	req := request{
+		Method:   http.MethodGet,
+		Resource: CAQL.path(),
+		Parameters: url.Values{
+			"query":  {query},
+			"start":  {strconv.FormatInt(start.Unix(), 10)},
+			"end":    {strconv.FormatInt(end.Unix(), 10)},
+			"period": {strconv.FormatInt(int64(period/time.Second), 10)},
+			"format": {caql_format},
+		},
+		Result: &response,
+	}
+
+	if _, err := c.send(ctx, req); err != nil {
+		var api *APIError
+		if errors.As(err, &api) && api.StatusCode == http.StatusBadRequest {
+			return nil, caqlError(query, api)
+		}
+		return nil, err
+	}
+	return response.result(FetchRequest{})
+}
+
+// Returns the CAQLError describing a query rejected by Circonus.
+func caqlError(query string, api *APIError) CAQLError {
+	message := string(api.Body)
+	if api.Circonus != nil {
+		if api.Circonus.Explanation != "" {
+			message = api.Circonus.Explanation
+		} else if api.Circonus.Message != "" {
+			message = api.Circonus.Message
+		}
+	}
+	return CAQLError{Query: query, Message: message, api: api}
+}
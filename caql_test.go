@@ -0,0 +1,131 @@
+package circonus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+
+// CAQL Server =========================================================== //
+
+
+/*
+ * Canned responses to CAQL queries, by query.
+ */
+var caqlResponses = map[string]string {
+	`metric:average("uuid", "requests")`: `{
+		"version": "DF4",
+		"head": { "start":1500000000, "period":60, "count":3 },
+		"meta": [ { "label":"requests", "kind":"numeric" } ],
+		"data": [ [ 1, 2.5, null ] ]
+	}`,
+	`metric:histogram("uuid", "latency")`: `{
+		"version": "DF4",
+		"head": { "start":1500000000, "period":60, "count":1 },
+		"meta": [ { "label":"latency", "kind":"histogram" } ],
+		"data": [ [ { "+12e-003":4, "+15e-003":1 } ] ]
+	}`,
+}
+
+
+/*
+ * Creates an API server answering CAQL queries with the canned responses
+ * above, and any other query with a syntax error.  The parameters of each
+ * query are sent to the given channel.
+ */
+func createCAQLServer(queries chan url.Values) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/caql", func(res http.ResponseWriter, req *http.Request) {
+		params := req.URL.Query()
+		queries <- params
+
+		response, known := caqlResponses[params.Get("query")]
+		if !known {
+			respond(res, http.StatusBadRequest, `{
+				"code": "Bad Request",
+				"explanation": "syntax error at position 7",
+				"message": "Invalid CAQL query"
+			}`)
+			return
+		}
+		respond(res, http.StatusOK, response)
+	})
+
+	return httptest.NewServer(http.StripPrefix("/" + supported_version, mux))
+}
+
+
+// Tests ================================================================= //
+
+
+func TestCAQL(t *testing.T) {
+	queries := make(chan url.Values, 1)
+	client := createClient(createCAQLServer(queries))
+	ctx := context.Background()
+	start := time.Unix(1500000000, 0)
+	end := start.Add(time.Duration(3) * time.Minute)
+
+	result, err := client.CAQL(ctx, `metric:average("uuid", "requests")`, start, end, time.Minute)
+	if err != nil {
+		t.Fatalf("CAQL failed unexpectedly: %s\n", err.Error())
+	}
+
+	params := <- queries
+	expect(t, params.Get("start"), "1500000000")
+	expect(t, params.Get("end"), "1500000180")
+	expect(t, params.Get("period"), "60")
+	expect(t, params.Get("format"), "DF4")
+
+	expect(t, len(result.Series), 1)
+	expect(t, result.Series[0].Label, "requests")
+	expect(t, len(result.Series[0].Points), 3)
+	expect(t, *result.Series[0].Points[1].Value, 2.5)
+	expect(t, result.Series[0].Points[1].Timestamp, time.Unix(1500000060, 0))
+	expect(t, result.Series[0].Points[2].Value == nil, true)
+
+	result, err = client.CAQL(ctx, `metric:histogram("uuid", "latency")`, start, end, time.Minute)
+	<- queries
+	if err != nil {
+		t.Fatalf("CAQL failed unexpectedly: %s\n", err.Error())
+	}
+	expect(t, result.Series[0].Kind, FETCH_KIND_HISTOGRAM)
+	expect(t, result.Series[0].Points[0].Histogram.Count(), uint64(5))
+}
+
+
+func TestCAQLErrors(t *testing.T) {
+	queries := make(chan url.Values, 1)
+	client := createClient(createCAQLServer(queries))
+	ctx := context.Background()
+	start := time.Unix(1500000000, 0)
+
+	_, err := client.CAQL(ctx, "metric:(", start, start.Add(time.Hour), time.Minute)
+	<- queries
+
+	var caqlErr CAQLError
+	if !errors.As(err, &caqlErr) {
+		t.Fatalf("Expected a CAQLError, got %v\n", err)
+	}
+	expect(t, caqlErr.Query, "metric:(")
+	expect(t, caqlErr.Message, "syntax error at position 7")
+	if !errors.Is(err, ErrCAQL) || !errors.Is(err, ErrBadRequest) {
+		t.Errorf("CAQLError does not match its sentinels\n")
+	}
+	var circonusErr CirconusError
+	if errors.As(err, &circonusErr) {
+		t.Errorf("CAQLError is also a CirconusError\n")
+	}
+
+	// Invalid windows are not sent
+	_, err = client.CAQL(ctx, "metric:(", start, start, time.Duration(500) * time.Millisecond)
+	var verr ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Expected a ValidationError, got %v\n", err)
+	}
+	expect(t, len(verr.Problems), 2)
+}
@@ -0,0 +1,386 @@
+package circonus
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"sync"
+)
+
+// Structures ============================================================ //
+
+// A CheckBundle is a collection of checks, of the same type and target,
+// distributed across one or more brokers.
+//
+// Fields prefixed with an underscore in Circonus' representation are
+// read-only and are ignored by Circonus when sent.
+type CheckBundle struct {
+	CID                   CID                 `json:"_cid,omitempty"`
+	Checks                []CID               `json:"_checks,omitempty"`
+	CheckUUIDs            []string            `json:"_check_uuids,omitempty"`
+	Created               int64               `json:"_created,omitempty"`
+	LastModified          int64               `json:"_last_modified,omitempty"`
+	LastModifiedBy        string              `json:"_last_modified_by,omitempty"`
+	ReverseConnectionURLs []string            `json:"_reverse_connection_urls,omitempty"`
+	Brokers               []CID               `json:"brokers"`
+	Config                CheckBundleConfig   `json:"config"`
+	DisplayName           string              `json:"display_name"`
+	MetricLimit           int                 `json:"metric_limit,omitempty"`
+	Metrics               []CheckBundleMetric `json:"metrics"`
+	Notes                 *string             `json:"notes"`
+	Period                int                 `json:"period"` // Seconds between checks
+	Status                string              `json:"status"`
+	Tags                  []string            `json:"tags"`
+	Target                string              `json:"target"`
+	Timeout               float64             `json:"timeout"` // Seconds before a check is abandoned
+	Type                  string              `json:"type"`
+}
+
+// Configuration of a check bundle.  Available keys vary by check type (for
+// example, an "httptrap" check accepts "asynch_metrics" and "secret").
+type CheckBundleConfig map[string]string
+
+// A CheckBundleMetric describes a single metric collected by a check bundle.
+type CheckBundleMetric struct {
+	Name   string   `json:"name"`
+	Result *string  `json:"result,omitempty"`
+	Status string   `json:"status"` // Either "active" or "available"
+	Tags   []string `json:"tags"`
+	Type   string   `json:"type"`
+	Units  *string  `json:"units"`
+}
+
+// Returns the URL metrics are submitted to for an HTTPTrap check bundle, or
+// an empty string for other types of check bundle.
+func (cb CheckBundle) SubmissionURL() string {
+	return cb.Config["submission_url"]
+}
+
+// A CheckBundleSpec describes a check bundle for EnsureCheckBundle to find
+// or create.  Bundles are identified by their type, target and display name,
+// and by having every one of SearchTags.
+type CheckBundleSpec struct {
+	DisplayName string
+	Target      string
+	Type        string              // Defaults to "httptrap"
+	SearchTags  []string            // Tags identifying the bundle (eg. "service:api")
+	Tags        []string            // Further tags the bundle must have
+	Metrics     []CheckBundleMetric // Metrics the bundle must have
+	Brokers     []CID               // If empty, the first suitable broker is used
+	Config      CheckBundleConfig   // Only used when creating the bundle
+	Period      int                 // Defaults to 60 seconds
+	Timeout     float64             // Defaults to 10 seconds
+
+	// OnDuplicates, if set, is called when several existing bundles match
+	// the spec, with the CID of the bundle returned (that with the lowest
+	// CID) and those of the others.
+	OnDuplicates func(kept CID, duplicates []CID)
+}
+
+// Constants & Data ====================================================== //
+
+const (
+	default_check_period  int     = 60
+	default_check_timeout float64 = 10
+)
+
+// EnsureCheckBundle calls in progress, by account and bundle, so that
+// concurrent calls for the same bundle within the process find rather than
+// duplicate each other's bundles, while calls for other bundles proceed.
+var provisioning = struct {
+	sync.Mutex
+	bySpec map[provisioningKey]*provisioningLock
+}{
+	bySpec: make(map[provisioningKey]*provisioningLock),
+}
+
+type provisioningKey struct {
+	host, token        string
+	kind, target, name string
+}
+
+type provisioningLock struct {
+	sync.Mutex
+	users int // Calls holding or waiting for the lock
+}
+
+// Check Bundle API ====================================================== //
+
+// Creates a new check bundle and returns it as stored by Circonus.
+func (c *Client) CreateCheckBundle(ctx context.Context, cb *CheckBundle) (*CheckBundle, error) {
+	return pointerTo(NewResource[CheckBundle](c, CHECK_BUNDLE).Create(ctx, *cb))
+}
+
+// Retrieves the check bundle with the given CID (eg. "/check_bundle/1234").
+func (c *Client) GetCheckBundle(ctx context.Context, cid CID) (*CheckBundle, error) {
+	return pointerTo(NewResource[CheckBundle](c, CHECK_BUNDLE).Get(ctx, cid))
+}
+
+// Replaces the configuration of an existing check bundle, identified by its
+// CID, and returns the updated bundle.
+func (c *Client) UpdateCheckBundle(ctx context.Context, cb *CheckBundle) (*CheckBundle, error) {
+	if cb.CID == "" {
+		return nil, RequestDataError{Reason: "check bundle has no CID"}
+	}
+	return pointerTo(NewResource[CheckBundle](c, CHECK_BUNDLE).Update(ctx, cb.CID, *cb))
+}
+
+// Deletes the check bundle with the given CID.
+func (c *Client) DeleteCheckBundle(ctx context.Context, cid CID) error {
+	return NewResource[CheckBundle](c, CHECK_BUNDLE).Delete(ctx, cid)
+}
+
+// Retrieves every check bundle visible to the Client's access token,
+// optionally filtered and paged by the given options.
+func (c *Client) ListCheckBundles(ctx context.Context, opts *ListOptions) ([]CheckBundle, error) {
+	return NewResource[CheckBundle](c, CHECK_BUNDLE).List(ctx, opts)
+}
+
+// Check Bundle Provisioning ============================================= //
+
+// Finds the check bundle described by a spec, creating it if it does not
+// exist, and returns it along with its submission URL (empty for bundles
+// other than HTTPTrap bundles).  Tags and metrics required by the spec but
+// missing from an existing bundle are added to it.
+//
+// Concurrent calls within a process are serialized, and so find rather than
+// duplicate each other's bundles.  Calls from many processes may each create
+// a bundle; having done so, each looks again and, should a bundle with a
+// lower CID exist, deletes its own and uses that one instead.  This relies
+// on Circonus listing newly created bundles straight away, so duplicates may
+// survive should it not.
+//
+// Existing bundles are never deleted.  If several match the spec, the one
+// with the lowest CID is returned, and the others are reported to the spec's
+// OnDuplicates function.
+func (c *Client) EnsureCheckBundle(ctx context.Context, spec CheckBundleSpec) (*CheckBundle, string, error) {
+	spec = spec.withDefaults()
+	unlock := c.lockSpec(spec)
+	defer unlock()
+
+	matches, err := c.findCheckBundles(ctx, spec)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var duplicates []CID
+	if len(matches) == 0 {
+		bundle, err := c.newCheckBundle(ctx, spec)
+		if err != nil {
+			return nil, "", err
+		}
+		created, err := c.CreateCheckBundle(ctx, bundle)
+		if err != nil {
+			return nil, "", err
+		}
+
+		// Look again for bundles created concurrently by other processes
+		if matches, err = c.findCheckBundles(ctx, spec); err != nil {
+			return nil, "", err
+		}
+		if len(matches) > 0 && cidLess(matches[0].CID, created.CID) {
+			err := c.DeleteCheckBundle(ctx, created.CID)
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				return nil, "", err
+			}
+			for _, duplicate := range matches[1:] {
+				if duplicate.CID != created.CID {
+					duplicates = append(duplicates, duplicate.CID)
+				}
+			}
+		} else {
+			matches = []CheckBundle{*created}
+		}
+	} else if len(matches) > 1 {
+		for _, duplicate := range matches[1:] {
+			duplicates = append(duplicates, duplicate.CID)
+		}
+	}
+
+	bundle := &matches[0]
+	if reconciled, changed := spec.reconcile(*bundle); changed {
+		if bundle, err = c.UpdateCheckBundle(ctx, &reconciled); err != nil {
+			return nil, "", err
+		}
+	}
+	if len(duplicates) > 0 && spec.OnDuplicates != nil {
+		spec.OnDuplicates(matches[0].CID, duplicates)
+	}
+	return bundle, bundle.SubmissionURL(), nil
+}
+
+// Returns the check bundles matching a spec, ordered by CID.
+func (c *Client) findCheckBundles(ctx context.Context, spec CheckBundleSpec) ([]CheckBundle, error) {
+	query := NewQuery().
+		Where("type", spec.Type).
+		Where("target", spec.Target).
+		Where("display_name", spec.DisplayName).
+		Tag(spec.SearchTags...)
+	bundles, err := c.ListCheckBundles(ctx, &ListOptions{Query: query})
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []CheckBundle{}
+	for _, bundle := range bundles {
+		if spec.matches(bundle) {
+			matches = append(matches, bundle)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return cidLess(matches[i].CID, matches[j].CID)
+	})
+	return matches, nil
+}
+
+// Returns a new check bundle as described by a spec.  HTTPTrap bundles are
+// given a random secret unless the spec configures one.
+func (c *Client) newCheckBundle(ctx context.Context, spec CheckBundleSpec) (*CheckBundle, error) {
+	bundle, _ := spec.reconcile(CheckBundle{
+		Brokers:     spec.Brokers,
+		Config:      CheckBundleConfig{},
+		DisplayName: spec.DisplayName,
+		Metrics:     []CheckBundleMetric{},
+		Period:      spec.Period,
+		Status:      "active",
+		Tags:        []string{},
+		Target:      spec.Target,
+		Timeout:     spec.Timeout,
+		Type:        spec.Type,
+	})
+
+	for key, value := range spec.Config {
+		bundle.Config[key] = value
+	}
+	if spec.Type == CHECK_TYPE_HTTPTRAP {
+		if bundle.Config["asynch_metrics"] == "" {
+			bundle.Config["asynch_metrics"] = "true"
+		}
+		if bundle.Config["secret"] == "" {
+			secret := make([]byte, 8)
+			if _, err := rand.Read(secret); err != nil {
+				return nil, err
+			}
+			bundle.Config["secret"] = hex.EncodeToString(secret)
+		}
+	}
+
+	if len(bundle.Brokers) == 0 {
+		brokers, err := c.FindBrokers(ctx, BrokerFilter{CheckType: spec.Type})
+		if err != nil {
+			return nil, err
+		}
+		if len(brokers) == 0 {
+			return nil, RequestDataError{Reason: "no broker supports " + spec.Type + " checks"}
+		}
+		bundle.Brokers = []CID{brokers[0].CID}
+	}
+
+	return &bundle, nil
+}
+
+// Acquires the lock serializing calls to EnsureCheckBundle for the bundle
+// described by a spec, returning the function releasing it.
+func (c *Client) lockSpec(spec CheckBundleSpec) func() {
+	key := provisioningKey{c.host, c.token, spec.Type, spec.Target, spec.DisplayName}
+
+	provisioning.Lock()
+	l, exists := provisioning.bySpec[key]
+	if !exists {
+		l = &provisioningLock{}
+		provisioning.bySpec[key] = l
+	}
+	l.users++
+	provisioning.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+
+		provisioning.Lock()
+		defer provisioning.Unlock()
+		if l.users--; l.users == 0 {
+			delete(provisioning.bySpec, key)
+		}
+	}
+}
+
+func (s CheckBundleSpec) withDefaults() CheckBundleSpec {
+	if s.Type == "" {
+		s.Type = CHECK_TYPE_HTTPTRAP
+	}
+	if s.Period <= 0 {
+		s.Period = default_check_period
+	}
+	if s.Timeout <= 0 {
+		s.Timeout = default_check_timeout
+	}
+	return s
+}
+
+// Reports whether a check bundle is the one described by a spec.
+func (s CheckBundleSpec) matches(cb CheckBundle) bool {
+	if cb.Type != s.Type || cb.Target != s.Target || cb.DisplayName != s.DisplayName {
+		return false
+	}
+	for _, tag := range s.SearchTags {
+		if !contains(cb.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns a copy of a check bundle with the tags and metrics required by a
+// spec, and whether any were missing or differed.  The type and status of a
+// spec's metrics are only compared if given, and otherwise default to
+// "numeric" and "active" for metrics which are added.
+func (s CheckBundleSpec) reconcile(cb CheckBundle) (CheckBundle, bool) {
+	changed := false
+
+	tags := append([]string{}, cb.Tags...)
+	for _, tag := range append(append([]string{}, s.SearchTags...), s.Tags...) {
+		if !contains(tags, tag) {
+			tags = append(tags, tag)
+			changed = true
+		}
+	}
+	cb.Tags = tags
+
+	metrics := append([]CheckBundleMetric{}, cb.Metrics...)
+	for _, metric := range s.Metrics {
+		found := false
+		for i := range metrics {
+			if metrics[i].Name != metric.Name {
+				continue
+			}
+			found = true
+			if metric.Type != "" && metrics[i].Type != metric.Type {
+				metrics[i].Type = metric.Type
+				changed = true
+			}
+			if metric.Status != "" && metrics[i].Status != metric.Status {
+				metrics[i].Status = metric.Status
+				changed = true
+			}
+		}
+		if !found {
+			if metric.Type == "" {
+				metric.Type = METRIC_NUMERIC
+			}
+			if metric.Status == "" {
+				metric.Status = "active"
+			}
+			if metric.Tags == nil {
+				metric.Tags = []string{}
+			}
+			metrics = append(metrics, metric)
+			changed = true
+		}
+	}
+	cb.Metrics = metrics
+
+	return cb, changed
+}
@@ -0,0 +1,367 @@
+package circonus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+
+// Bundle Server ========================================================= //
+
+
+/*
+ * An API server keeping check bundles in memory, with a single broker
+ * supporting HTTPTrap checks.  Created bundles submit to the given URL.  If
+ * set, racing is called with each bundle created, before it is stored, to
+ * simulate other processes creating bundles at the same time.
+ */
+type bundleServer struct {
+	*httptest.Server
+	mutex		sync.Mutex
+	bundles	map[CID]CheckBundle
+	next		int
+	created	int
+	updated	int
+	deleted	int
+	racing	func(CheckBundle)
+}
+
+
+func createBundleServer(submissionURL string) *bundleServer {
+	server := &bundleServer { bundles:make(map[CID]CheckBundle), next:1000 }
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/broker", func(res http.ResponseWriter, req *http.Request) {
+		respond(res, http.StatusOK, `[
+			{ "_cid":"/broker/2", "_details":[ { "status":"active", "modules":["json"] } ] },
+			{ "_cid":"/broker/1", "_details":[ { "status":"active", "modules":["httptrap"] } ] }
+		]`)
+	})
+	mux.HandleFunc("/check_bundle", func(res http.ResponseWriter, req *http.Request) {
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+
+		if req.Method == http.MethodGet {
+			bundles := []CheckBundle{}
+			for _, bundle := range server.bundles {
+				bundles = append(bundles, bundle)
+			}
+			encoded, _ := json.Marshal(bundles)
+			respond(res, http.StatusOK, string(encoded))
+			return
+		}
+
+		var bundle CheckBundle
+		json.NewDecoder(req.Body).Decode(&bundle)
+		bundle.CID = CID("/check_bundle/" + strconv.Itoa(server.next))
+		bundle.Config["submission_url"] = submissionURL + "/module/httptrap/uuid/" + bundle.Config["secret"]
+		if server.racing != nil {
+			server.racing(bundle)
+		}
+		server.bundles[bundle.CID] = bundle
+		server.next++
+		server.created++
+
+		encoded, _ := json.Marshal(bundle)
+		respond(res, http.StatusOK, string(encoded))
+	})
+	mux.HandleFunc("/check_bundle/", func(res http.ResponseWriter, req *http.Request) {
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+
+		cid := CID(strings.TrimSuffix(req.URL.Path, "/"))
+		if _, exists := server.bundles[cid]; !exists {
+			respond(res, http.StatusNotFound, createCirconusError())
+			return
+		}
+
+		switch req.Method {
+		case http.MethodDelete:
+			delete(server.bundles, cid)
+			server.deleted++
+			res.WriteHeader(http.StatusNoContent)
+		case http.MethodPut:
+			var bundle CheckBundle
+			json.NewDecoder(req.Body).Decode(&bundle)
+			server.bundles[cid] = bundle
+			server.updated++
+			encoded, _ := json.Marshal(bundle)
+			respond(res, http.StatusOK, string(encoded))
+		default:
+			encoded, _ := json.Marshal(server.bundles[cid])
+			respond(res, http.StatusOK, string(encoded))
+		}
+	})
+
+	server.Server = httptest.NewServer(http.StripPrefix("/" + supported_version, mux))
+	return server
+}
+
+
+/*
+ * Stores a check bundle directly, as though created by another process.
+ */
+func (s *bundleServer) store(bundle CheckBundle) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.storeLocked(bundle)
+}
+
+
+func (s *bundleServer) storeLocked(bundle CheckBundle) {
+	s.bundles[bundle.CID] = bundle
+}
+
+
+// Tests ================================================================= //
+
+
+func TestCheckBundleJSON(t *testing.T) {
+	payload := `{
+		"_cid": "/check_bundle/1234",
+		"_checks": [ "/check/5678" ],
+		"_check_uuids": [ "0a1b2c3d-4e5f-6789-abcd-ef0123456789" ],
+		"_created": 1500000000,
+		"_last_modified": 1500000600,
+		"_last_modified_by": "/user/42",
+		"_reverse_connection_urls": [ "mtev_reverse://10.0.0.1:43191/check/0a1b2c3d" ],
+		"brokers": [ "/broker/1" ],
+		"config": {
+			"asynch_metrics": "true",
+			"secret": "a1b2c3d4",
+			"submission_url": "https://trap.example.com/module/httptrap/0a1b2c3d/a1b2c3d4"
+		},
+		"display_name": "api metrics",
+		"metric_limit": 500,
+		"metrics": [
+			{ "name": "requests", "status": "active", "tags": [], "type": "numeric", "units": null },
+			{ "name": "latency", "status": "active", "tags": [ "service:api" ], "type": "histogram", "units": "seconds" }
+		],
+		"notes": null,
+		"period": 60,
+		"status": "active",
+		"tags": [ "service:api" ],
+		"target": "api.example.com",
+		"timeout": 10,
+		"type": "httptrap"
+	}`
+
+	var cb CheckBundle
+	if err := json.Unmarshal([]byte(payload), &cb); err != nil {
+		t.Fatalf("Cannot decode check bundle: %s\n", err.Error())
+	}
+	expect(t, cb.CID, CID("/check_bundle/1234"))
+	expect(t, cb.Checks[0], CID("/check/5678"))
+	expect(t, cb.CheckUUIDs[0], "0a1b2c3d-4e5f-6789-abcd-ef0123456789")
+	expect(t, cb.Created, int64(1500000000))
+	expect(t, cb.LastModified, int64(1500000600))
+	expect(t, cb.LastModifiedBy, "/user/42")
+	expect(t, len(cb.ReverseConnectionURLs), 1)
+	expect(t, cb.Brokers[0], CID("/broker/1"))
+	expect(t, cb.SubmissionURL(), "https://trap.example.com/module/httptrap/0a1b2c3d/a1b2c3d4")
+	expect(t, cb.MetricLimit, 500)
+	expect(t, len(cb.Metrics), 2)
+	expect(t, cb.Metrics[0].Units == nil, true)
+	expect(t, *cb.Metrics[1].Units, "seconds")
+	expect(t, cb.Notes == nil, true)
+	expect(t, cb.Period, 60)
+	expect(t, cb.Timeout, float64(10))
+	expect(t, cb.Type, CHECK_TYPE_HTTPTRAP)
+
+	// Encoding the bundle again reproduces the payload
+	encoded, _ := json.Marshal(cb)
+	var original, roundtrip map[string]interface{}
+	json.Unmarshal([]byte(payload), &original)
+	json.Unmarshal(encoded, &roundtrip)
+	if !reflect.DeepEqual(original, roundtrip) {
+		t.Errorf("Check bundle did not survive a round trip:\n%s\n", encoded)
+	}
+
+	// New bundles leave out read-only fields, but send null notes and units
+	notes := "created by hand"
+	created := CheckBundle {
+		DisplayName:	"web metrics",
+		Metrics:			[]CheckBundleMetric{ { Name:"requests", Status:"active", Type:"numeric" } },
+		Notes:				&notes,
+	}
+	encoded, _ = json.Marshal(created)
+	var body map[string]json.RawMessage
+	json.Unmarshal(encoded, &body)
+	for _, field := range []string{ "_cid", "_checks", "_check_uuids", "_created", "_last_modified", "_last_modified_by", "_reverse_connection_urls" } {
+		if _, exists := body[field]; exists {
+			t.Errorf("New check bundle sent read-only field %s\n", field)
+		}
+	}
+	expect(t, string(body["notes"]), `"created by hand"`)
+	expect(t, string(body["metrics"]), `[{"name":"requests","status":"active","tags":null,"type":"numeric","units":null}]`)
+}
+
+
+func TestEnsureCheckBundle(t *testing.T) {
+	server := createBundleServer("http://trap.example.com")
+	defer server.Close()
+	client := createClient(server.Server)
+	ctx := context.Background()
+
+	spec := CheckBundleSpec {
+		DisplayName:	"api metrics",
+		Target:				"api.example.com",
+		SearchTags:		[]string{ "service:api" },
+		Metrics:			[]CheckBundleMetric{ { Name:"requests", Status:"active", Type:"numeric" } },
+	}
+
+	// Concurrent calls create a single bundle
+	var wg sync.WaitGroup
+	cids := make(chan CID, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			bundle, url, err := client.EnsureCheckBundle(ctx, spec)
+			if err != nil {
+				t.Errorf("EnsureCheckBundle failed unexpectedly: %s\n", err.Error())
+				return
+			}
+			if !strings.HasPrefix(url, "http://trap.example.com/module/httptrap/") {
+				t.Errorf("Unexpected submission URL %q\n", url)
+			}
+			cids <- bundle.CID
+		}()
+	}
+	wg.Wait()
+	close(cids)
+
+	for cid := range cids {
+		expect(t, cid, CID("/check_bundle/1000"))
+	}
+	expect(t, server.created, 1)
+	expect(t, server.updated, 0)
+	bundle := server.bundles["/check_bundle/1000"]
+	expect(t, bundle.Type, CHECK_TYPE_HTTPTRAP)
+	expect(t, bundle.Brokers[0], CID("/broker/1"))
+	expect(t, bundle.Config["asynch_metrics"], "true")
+
+	// Ensuring the same spec again changes nothing
+	again, _, err := client.EnsureCheckBundle(ctx, spec)
+	if err != nil {
+		t.Fatalf("EnsureCheckBundle failed unexpectedly: %s\n", err.Error())
+	}
+	expect(t, again.CID, CID("/check_bundle/1000"))
+	expect(t, server.created, 1)
+	expect(t, server.updated, 0)
+
+	// Nor does leaving the type and status of metrics unspecified
+	loose := spec
+	loose.Metrics = []CheckBundleMetric{ { Name:"requests" } }
+	if _, _, err := client.EnsureCheckBundle(ctx, loose); err != nil {
+		t.Fatalf("EnsureCheckBundle failed unexpectedly: %s\n", err.Error())
+	}
+	expect(t, server.updated, 0)
+
+	// Existing duplicates are reported but kept, and drift is corrected
+	duplicate := bundle
+	duplicate.CID = "/check_bundle/999"
+	duplicate.Tags = []string{ "service:api", "owner:ops" }
+	duplicate.Metrics = []CheckBundleMetric{}
+	server.store(duplicate)
+
+	var kept CID
+	var duplicates []CID
+	reporting := spec
+	reporting.OnDuplicates = func(k CID, d []CID) { kept, duplicates = k, d }
+	found, _, err := client.EnsureCheckBundle(ctx, reporting)
+	if err != nil {
+		t.Fatalf("EnsureCheckBundle failed unexpectedly: %s\n", err.Error())
+	}
+	expect(t, kept, CID("/check_bundle/999"))
+	expect(t, len(duplicates), 1)
+	expect(t, duplicates[0], CID("/check_bundle/1000"))
+	expect(t, found.CID, CID("/check_bundle/999"))
+	expect(t, len(server.bundles), 2)
+	expect(t, server.deleted, 0)
+	expect(t, server.updated, 1)
+	expect(t, len(found.Metrics), 1)
+	expect(t, found.Metrics[0].Status, "active")
+	expect(t, len(found.Tags), 2)
+
+	// Bundles with a different target are left alone
+	spec.Target = "web.example.com"
+	other, _, err := client.EnsureCheckBundle(ctx, spec)
+	if err != nil {
+		t.Fatalf("EnsureCheckBundle failed unexpectedly: %s\n", err.Error())
+	}
+	expect(t, other.CID, CID("/check_bundle/1001"))
+	expect(t, len(server.bundles), 3)
+}
+
+
+func TestEnsureCheckBundleRace(t *testing.T) {
+	server := createBundleServer("http://trap.example.com")
+	defer server.Close()
+	client := createClient(server.Server)
+	ctx := context.Background()
+
+	spec := CheckBundleSpec { DisplayName:"api metrics", Target:"api.example.com" }
+
+	// Another process creating a bundle first wins, and ours is deleted
+	server.racing = func(bundle CheckBundle) {
+		server.racing = nil
+		winner := bundle
+		winner.CID = "/check_bundle/10"
+		server.storeLocked(winner)
+	}
+	bundle, _, err := client.EnsureCheckBundle(ctx, spec)
+	if err != nil {
+		t.Fatalf("EnsureCheckBundle failed unexpectedly: %s\n", err.Error())
+	}
+	expect(t, bundle.CID, CID("/check_bundle/10"))
+	expect(t, server.deleted, 1)
+	expect(t, len(server.bundles), 1)
+
+	// Another process creating a bundle later loses, and its bundle is kept
+	// for it to delete
+	spec.Target = "web.example.com"
+	server.racing = func(bundle CheckBundle) {
+		server.racing = nil
+		loser := bundle
+		loser.CID = "/check_bundle/5000"
+		server.storeLocked(loser)
+	}
+	bundle, _, err = client.EnsureCheckBundle(ctx, spec)
+	if err != nil {
+		t.Fatalf("EnsureCheckBundle failed unexpectedly: %s\n", err.Error())
+	}
+	expect(t, bundle.CID, CID("/check_bundle/1001"))
+	expect(t, server.deleted, 1)
+	expect(t, len(server.bundles), 3)
+
+	// Other bundles found alongside the winner are reported as duplicates
+	spec.Target = "db.example.com"
+	server.racing = func(bundle CheckBundle) {
+		server.racing = nil
+		for _, cid := range []CID{ "/check_bundle/20", "/check_bundle/30" } {
+			other := bundle
+			other.CID = cid
+			server.storeLocked(other)
+		}
+	}
+	var kept CID
+	var duplicates []CID
+	spec.OnDuplicates = func(k CID, d []CID) { kept, duplicates = k, d }
+	bundle, _, err = client.EnsureCheckBundle(ctx, spec)
+	if err != nil {
+		t.Fatalf("EnsureCheckBundle failed unexpectedly: %s\n", err.Error())
+	}
+	expect(t, bundle.CID, CID("/check_bundle/20"))
+	expect(t, kept, CID("/check_bundle/20"))
+	expect(t, len(duplicates), 1)
+	expect(t, duplicates[0], CID("/check_bundle/30"))
+	expect(t, server.deleted, 2)
+}
@@ -0,0 +1,111 @@
+package circonus
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Structures ============================================================ //
+
+// A CID identifies a single item of a Circonus resource, in the form
+// "/<resource>/<id>" (eg. "/check_bundle/1234").
+//
+// Methods accepting a CID also accept a bare ID (eg. "1234"), which is taken
+// to belong to the resource the method operates on.  Either form is checked
+// against the ID format of its resource before any request is made.
+type CID string
+
+// Constants & Data ====================================================== //
+
+var (
+	numericID = regexp.MustCompile(`^[0-9]+$`)
+	currentID = regexp.MustCompile(`^([0-9]+|current)$`)
+	ruleSetID = regexp.MustCompile(`^[0-9]+(_.+)?$`)
+	uuidID    = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// Formats of the IDs of each resource.  IDs of resources not listed here are
+// not checked beyond being non-empty.
+var idFormats = map[resource]*regexp.Regexp{
+	ACCOUNT:         currentID,
+	ACKNOWLEDGEMENT: numericID,
+	ALERT:           numericID,
+	ANNOTATION:      numericID,
+	BROKER:          numericID,
+	CHECK:           numericID,
+	CHECK_BUNDLE:    numericID,
+	CONTACT_GROUP:   numericID,
+	GRAPH:           uuidID,
+	MAINTENANCE:     numericID,
+	RULE_SET:        ruleSetID,
+	RULE_SET_GROUP:  numericID,
+	TEMPLATE:        numericID,
+	USER:            currentID,
+}
+
+// CID Parsing =========================================================== //
+
+// Parses either a full CID or a bare ID belonging to the given resource,
+// returning it as a full CID.  The resource may be empty when parsing a full
+// CID, in which case the CID may belong to any resource.
+func ParseCID(r resource, s string) (CID, error) {
+	kind, id := r, s
+	if strings.HasPrefix(s, "/") {
+		parts := strings.SplitN(s[1:], "/", 2)
+		if len(parts) != 2 {
+			return "", InvalidCIDError{CID: s, Reason: "expected the form /<resource>/<id>"}
+		}
+		kind, id = resource(parts[0]), parts[1]
+		if r != "" && kind != r {
+			return "", InvalidCIDError{CID: s, Reason: "expected a " + string(r) + " CID"}
+		}
+	} else if r == "" {
+		return "", InvalidCIDError{CID: s, Reason: "bare ID given without a resource"}
+	}
+
+	if kind == "" || id == "" {
+		return "", InvalidCIDError{CID: s, Reason: "missing resource or ID"}
+	}
+	if strings.Contains(id, "/") {
+		return "", InvalidCIDError{CID: s, Reason: "ID cannot contain \"/\""}
+	}
+	if format, known := idFormats[kind]; known && !format.MatchString(id) {
+		return "", InvalidCIDError{CID: s, Reason: "not a valid " + string(kind) + " ID"}
+	}
+
+	return CID("/" + string(kind) + "/" + id), nil
+}
+
+// Returns the resource a full CID belongs to, or an empty resource for a
+// bare ID.
+func (c CID) Resource() resource {
+	if !strings.HasPrefix(string(c), "/") {
+		return ""
+	}
+	return resource(strings.SplitN(string(c)[1:], "/", 2)[0])
+}
+
+// Returns the ID portion of a CID.
+func (c CID) ID() string {
+	s := string(c)
+	if i := strings.LastIndex(s, "/"); i >= 0 {
+		return s[i+1:]
+	}
+	return s
+}
+
+// Checks that a CID is well formed, and its ID valid for its resource.
+func (c CID) Validate() error {
+	_, err := ParseCID("", string(c))
+	return err
+}
+
+func (c CID) String() string {
+	return string(c)
+}
+
+// Returns the request path of a single item of a resource.
+func (r resource) item(cid CID) (string, error) {
+	parsed, err := ParseCID(r, string(cid))
+	return string(parsed), err
+}
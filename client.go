@@ -1,20 +1,24 @@
 package circonus
 
 import (
-//"fmt"
 	"bytes"
+	"context"
 	"encoding/json"
+	"io"
 	"net/http"
+	"net/url"
 	"time"
 )
 
 // Structures ============================================================ //
 
-// A Client is a Circonus client.  Its zero value is a usable client with
-// default timeouts and retries.
+// A Client is a Circonus client.  Clients are created with NewClient, which
+// configures them with default timeouts and retries.
 // 
-// Although Clients should be safe for concurrent use, in practice doing so
-// will provide little benefit as Circonus rate limits use by access token.
+// Clients are safe for concurrent use, provided their exported fields are
+// not modified while requests are in flight.  In practice doing so will
+// provide little benefit as Circonus rate limits use by access token; see
+// SetRateLimit to avoid exceeding those limits in the first place.
 // 
 // Clients typically maintain internal (cached) state and so should be reused 
 // rather than created as needed.
@@ -23,9 +27,16 @@ type Client struct {
 	// that has failed because of rate limiting.  After the maximum number of
 	// retries has been attempted, a RateLimitExceededError will be returned.
 	// 
-	// The default value is five attempts.
+	// The default value is five attempts, and a value of zero or less makes
+	// a single attempt.  Retries is ignored if a RetryPolicy is set.
 	Retries int
 
+	// RetryPolicy decides whether and when failed requests are retried.
+	//
+	// If nil, an ExponentialBackoff policy is used which retries only rate
+	// limited requests, up to the number of attempts given by Retries.
+	RetryPolicy RetryPolicy
+
 	// Timeout specifies a time limit for requests made by the Client.  This
 	// includes connection time and reading the response.  The timer will
 	// interrupt request processing when exceeded, cancelling the request.
@@ -35,13 +46,14 @@ type Client struct {
 	// The default value is 30 seconds.
 	Timeout time.Duration
 
-	app         string          // Circonus: Application name
-	host        string          // Cironus API host
-	httpclient  *http.Client
-	path        string          // Base URL path of any requests made
-	results     chan result     // Used to limit client to a one request at a time
-	token       string          // Circonus: API token
-	transport   *http.Transport // For testing
+	app         string            // Circonus: Application name
+	host        string            // Cironus API host
+	httpclient  *http.Client      // If set, used instead of the transport
+	limiter     *RateLimiter      // Shared by all Clients with the same token
+	path        string            // Base URL path of any requests made
+	token       string            // Circonus: API token
+	transport   http.RoundTripper // If nil, http.DefaultTransport is used
+	useragent   string            // If empty, Go's default is used
 }
 
 // Internal type for encapsulating requests to send to Circonus.
@@ -49,13 +61,14 @@ type request struct {
 	Method     string
 	Resource   string
 	Data       interface{}
-	Parameters map[string]string
+	Parameters url.Values
+	Result     interface{} // If set, a successful response is decoded into it
 }
 
-// Internal type for representing a response from Circonus.
-type result struct {
-	Response	interface{}
-	Error     error
+// Internal interface for request data which can be checked before it is sent
+// to Circonus.
+type validator interface {
+	Validate() error
 }
 
 // Internal type for representing valid Circonus endpoints.
@@ -65,103 +78,227 @@ type resource string
 
 // Resource endpoint designators for use with convenience functions.
 const (
-	ACCOUNT        resource = "account"
-	BROKER         resource = "broker"
-	CHECK          resource = "check"
-	CHECK_BUNDLE   resource = "checkbundle"
-	CONTACT_GROUP  resource = "contact_group"
-	GRAPH          resource = "graph"
-	RULE_SET       resource = "rule_set"
-	RULE_SET_GROUP resource = "rule_set_group"
-	TEMPLATE       resource = "template"
-	USER           resource = "user"
+	ACCOUNT         resource = "account"
+	ACKNOWLEDGEMENT resource = "acknowledgement"
+	ALERT           resource = "alert"
+	ANNOTATION      resource = "annotation"
+	BROKER          resource = "broker"
+	CAQL            resource = "caql"
+	CHECK           resource = "check"
+	CHECK_BUNDLE    resource = "check_bundle"
+	CONTACT_GROUP   resource = "contact_group"
+	FETCH           resource = "fetch"
+	GRAPH           resource = "graph"
+	MAINTENANCE     resource = "maintenance"
+	RULE_SET        resource = "rule_set"
+	RULE_SET_GROUP  resource = "rule_set_group"
+	TEMPLATE        resource = "template"
+	USER            resource = "user"
 )
 
 const (
 	default_host           string = "https://api.circonus.com"
 	default_retry_attempts int = 5
-	default_retry_interval time.Duration = time.Duration(1) * time.Second
 	default_timeout        time.Duration = time.Duration(30) * time.Second
 	supported_version      string = "v2"
 )
 
+// Returns the request path of a resource's collection endpoint.
+func (r resource) path() string {
+	return "/" + string(r)
+}
+
 // Client API ============================================================ //
 
 // Creates a new Client for use with Circonus account matching the given
 // application identifier and account access token.
-func NewClient(appname string, apitoken string) Client {
-	return Client{
+//
+// By default, Clients use the public Circonus API.  Options may be given to
+// change this and other settings; an error is returned if any are invalid.
+func NewClient(appname string, apitoken string, options ...Option) (*Client, error) {
+	c := &Client{
 		Retries:   default_retry_attempts,
 		Timeout:   default_timeout,
 		app:       appname,
 		host:      default_host,
 		path:      "/" + supported_version,
-		results:   make(chan result),
 		token:     apitoken,
-		transport: &http.Transport{},
 	}
+
+	for _, option := range options {
+		if err := option(c); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
 }
 
 // Send a request to Circonus and return the response it returns.
 // 
-// If Circonus throttles a request because of rate limiting, it will be
-// retried until it succeeds, errors, or exceeds the configured number of
-// retry attempts.
-func (c *Client) send(r request) (interface{}, error) {
-	var res interface{}
-	var err error
-
-	if c.httpclient == nil {
-    c.httpclient = &http.Client{
-      Timeout:   c.Timeout,
-      Transport: c.transport,
-    }
-  }
-
-	go func(req request, channel chan result) {
-		for i := 0; i < c.Retries; i++ {
-			res, err = c.tryRequest(r, c.results)
-
-			if err != nil {
-				switch err.(type) {
-				case RateLimitError:
-					<- time.Tick(default_retry_interval)
-					if i == c.Retries - 1 {
-						err = RateLimitExceededError{}
-					}
-					continue
-				default:
-					break  // Stop on general errors
-				}
+// Failed requests are retried for as long as the Client's RetryPolicy allows.
+// By default, only requests throttled by Circonus because of rate limiting
+// are retried.  If the Client has a RateLimiter, every attempt waits for it.
+//
+// Requests using methods their resource does not support, and request data
+// able to validate itself, are checked before anything is sent.
+//
+// Cancelling the given context aborts the request, including any wait
+// between retries, and returns a RequestCanceledError.
+func (c *Client) send(ctx context.Context, r request) (interface{}, error) {
+	if err := verifyMethod(r); err != nil {
+		return nil, err
+	}
+	if v, ok := r.Data.(validator); ok {
+		if err := v.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	policy := c.RetryPolicy
+	if policy == nil {
+		// Unlike the policy's own default, no retries means a single attempt
+		attempts := c.Retries
+		if attempts < 1 {
+			attempts = 1
+		}
+		policy = ExponentialBackoff{MaxAttempts: attempts}
+	}
+
+	httpclient := c.httpClient()
+	start := time.Now()
+
+	for attempt := 1; ; attempt++ {
+		if c.limiter != nil {
+			if err := c.limiter.Wait(ctx); err != nil {
+				return nil, err
 			}
+		}
+
+		res, status, err := c.tryRequest(ctx, httpclient, r)
+		if err == nil {
+			return res, nil  // Stop immediately upon success
+		}
+		if ctx.Err() != nil {
+			return nil, RequestCanceledError{Err: ctx.Err()}
+		}
 
-			break  // Stop immediately upon success
+		a := RetryAttempt{
+			Attempt:    attempt,
+			Elapsed:    time.Since(start),
+			Method:     r.Method,
+			StatusCode: status,
+			Err:        err,
 		}
-		channel <- result{
-			Response: res,
-			Error:    err,
+		if rl, ok := err.(RateLimitError); ok {
+			a.RetryAfter = rl.RetryAfter
 		}
-	}(r, c.results)
 
-	// Await successful response or maximum retries
-	for {
-		select {
-			case res := <- c.results:
-				return res.Response, res.Error
+		delay, retry := policy.Backoff(a)
+		if !retry {
+			if rl, ok := err.(RateLimitError); ok {
+				return nil, RateLimitExceededError{api: rl.api}
+			}
+			return nil, err
+		}
+		if werr := wait(ctx, delay); werr != nil {
+			return nil, werr
 		}
 	}
 }
 
-// Attempts to send a single request to Circonus, process its response, and
-// return the results over a given channel.
-func (c *Client) tryRequest(r request, channel chan result) (interface{}, error) {
+// Returns an HTTP client configured with the Client's current settings, or
+// the HTTP client the Client was created with.
+//
+// HTTP clients are cheap to create and share the Client's transport, so one
+// is created for each request rather than being cached on the Client, which
+// would require synchronizing concurrent requests.
+func (c *Client) httpClient() *http.Client {
+	if c.httpclient != nil {
+		return c.httpclient
+	}
+
+	client := &http.Client{
+		Timeout: c.Timeout,
+	}
+	if c.transport != nil {
+		client.Transport = c.transport
+	}
+	return client
+}
+
+// Returns the error describing an unsuccessful response from Circonus.
+// Details of the error given by Circonus are included if available.
+func responseError(req *http.Request, res *http.Response, r request) error {
+	body, err := io.ReadAll(res.Body)
+	api := newAPIError(req, res, body, err)
+
+	var details CirconusError
+	if err == nil {
+		if err := json.Unmarshal(body, &details); err != nil {
+			api.Err = err
+		} else if details != (CirconusError{}) {
+			details.api = api
+			api.Circonus = &details
+		}
+	}
+
+	switch res.StatusCode {
+	case 401:
+		return TokenNotValidatedError{api: api}
+	case 403:
+		return AccessDeniedError{api: api}
+	case 404:
+		return ResourceNotFoundError{Endpoint: r.Resource, api: api}
+	case 429:
+		return RateLimitError{RetryAfter: retryAfter(res.Header), api: api}
+	}
+
+	if api.Circonus == nil {
+		reason := "no error details given"
+		if api.Err != nil {
+			reason = api.Err.Error()
+		}
+		return MalformedResponseError{Reason: reason, api: api}
+	}
+	return *api.Circonus
+}
+
+// Creates an APIError describing an exchange with Circonus.
+func newAPIError(req *http.Request, res *http.Response, body []byte, err error) *APIError {
+	return &APIError{
+		StatusCode: res.StatusCode,
+		Method:     req.Method,
+		Endpoint:   req.URL.String(),
+		Body:       body,
+		RequestID:  res.Header.Get("X-Request-Id"),
+		Err:        err,
+	}
+}
+
+// Pauses for the given duration, returning early with an error if the
+// context is cancelled first.
+func wait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <- timer.C:
+		return nil
+	case <- ctx.Done():
+		return RequestCanceledError{Err: ctx.Err()}
+	}
+}
+
+// Attempts to send a single request to Circonus and process its response,
+// returning the response's status code, or zero if none was received.
+func (c *Client) tryRequest(ctx context.Context, httpclient *http.Client, r request) (interface{}, int, error) {
 	var response interface{}
 
 	// Encode data as JSON
 	encoded_data := new(bytes.Buffer)
 	if r.Data != nil {
 		if encoded, err := json.Marshal(r.Data); err != nil {
-			return nil, RequestDataError{Reason: err.Error()}
+			return nil, 0, RequestDataError{Reason: err.Error()}
 		} else {
 			encoded_data = bytes.NewBuffer(encoded)
 		}
@@ -169,63 +306,66 @@ func (c *Client) tryRequest(r request, channel chan result) (interface{}, error)
 
 	// Create request
 	url := c.host + c.path + r.Resource
-	req, err := http.NewRequest(r.Method, url, encoded_data)
+	req, err := http.NewRequestWithContext(ctx, r.Method, url, encoded_data)
 	if err != nil {
-		return nil, err  // Should only occur with malformed request URL's
+		return nil, 0, err  // Should only occur with malformed request URL's
 	}
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("X-Circonus-App-Name", c.app)
 	req.Header.Set("X-Circonus-Auth-Token", c.token)
+	if c.useragent != "" {
+		req.Header.Set("User-Agent", c.useragent)
+	}
 
 	// Add any querystring parameters
 	if len(r.Parameters) > 0 {
 		q := req.URL.Query()
-		for key, value := range r.Parameters {
-			q.Set(key, value)
+		for key, values := range r.Parameters {
+			q[key] = append(q[key], values...)
 		}
 		req.URL.RawQuery = q.Encode()
 	}
 
 	// Execute request
-	res, err := c.httpclient.Do(req)
+	res, err := httpclient.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer res.Body.Close()
 
-	decoder := json.NewDecoder(res.Body)
-
 	// Handle errors
 	if res.StatusCode > 399 {
-		switch res.StatusCode {
-		case 401:
-			return nil, TokenNotValidatedError{}
-		case 403:
-			return nil, AccessDeniedError{}
-		case 404:
-			return nil, ResourceNotFoundError{Endpoint: r.Resource}
-		case 429:
-			return nil, RateLimitError{}
-		}
+		return nil, res.StatusCode, responseError(req, res, r)
+	}
 
-		// Parse response as an Error
-		var errorResult CirconusError
-		if err := decoder.Decode(&errorResult); err != nil {
-			return nil, MalformedResponseError{Reason: err.Error()}
-		} else {
-			return nil, errorResult
-		}
+	// Deletions and some updates return no content
+	if res.StatusCode == http.StatusNoContent {
+		return nil, res.StatusCode, nil
 	}
 
 	// Parse successful response
-	if err := decoder.Decode(&response); err != nil {
-		if err.Error() == "EOF" {
-			return nil, EmptyResponseError{}
-		} else {
-			return nil, MalformedResponseError{Reason: err.Error()}
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, res.StatusCode, err
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, res.StatusCode, EmptyResponseError{api: newAPIError(req, res, body, nil)}
+	}
+
+	var target interface{} = &response
+	if r.Result != nil {
+		target = r.Result
+	}
+	if err := json.Unmarshal(body, target); err != nil {
+		return nil, res.StatusCode, MalformedResponseError{
+			Reason: err.Error(),
+			api:    newAPIError(req, res, body, err),
 		}
 	}
 
-	return response, nil
+	if r.Result != nil {
+		return r.Result, res.StatusCode, nil
+	}
+	return response, res.StatusCode, nil
 }
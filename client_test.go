@@ -1,12 +1,16 @@
 package circonus
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"reflect"
+	"strconv"
+	"sync"
 	"testing"
 	"time"
 )
@@ -21,6 +25,7 @@ var (
 	values            chan item     = make(chan item)
 	defaultTimeout		time.Duration	= time.Duration(500) * time.Millisecond
 	failureCounter 		int						= 0
+	failureMutex			sync.Mutex
 	listener          valueListener = NewValueListener(values)
 	malformedJson 		string				= "{ count:4 )"
 	successJson 			string				= "{ \"data\":[1,2,3,4] }"
@@ -52,32 +57,32 @@ func createCirconusError() string {
 	}
 	cerror, err := json.Marshal(ce)
 	if err != nil {
-		fmt.Errorf("Bad factory function: createCirconusError()\n")
+		panic("Bad factory function: createCirconusError()")
 	}
 	return string(cerror)
 }
 
 
 /* 
- * Creates a Client configured for testing.
+ * Creates a Client configured for testing, with short timeouts and retry
+ * intervals.
  * 
  * Arguments:
  *		server	Server Client requests will be proxied to.
  */
-func createClient(server *httptest.Server) Client {
-	serverURL, err := url.Parse(server.URL)
-
-	tr := &http.Transport {
-		Proxy:	func(req *http.Request) (*url.URL, error) {
-			return serverURL, err
-		},
+func createClient(server *httptest.Server) *Client {
+	policy := ExponentialBackoff {
+		InitialInterval:	time.Duration(10) * time.Millisecond,
+		MaxInterval:			time.Duration(50) * time.Millisecond,
 	}
 
-	client := NewClient("sampleapp", "abc123")
+	client, err := NewClient("sampleapp", "abc123",
+		WithHost(server.URL),
+		WithRetryPolicy(policy))
+	if err != nil {
+		panic("Bad factory function: createClient(): " + err.Error())
+	}
 	client.Timeout = defaultTimeout
-	client.host = serverURL.String()
-	client.path = ""
-	client.transport = tr
 	return client
 }
 
@@ -88,55 +93,70 @@ func createClient(server *httptest.Server) Client {
  * 
  * The following resources are exposed:
  * 
+ *   /echo								- 200 response echoing the "id" parameter.
  *   /empty								- Empty server response.
  *   /failure     				- 500 response with valid body content.
+ *   /items								- Pages through a list of 250 numbered items.
+ *   /items-unpaged				- Every one of 250 numbered items, however paged.
  *   /malformed-failure		- 500 response with malformed JSON.
  *   /malformed-success		- 200 response with malformed JSON.
  *   /rate-limit-partial	- 429 response that returns 200 after two attempts.
  *   /rate-limit-full			- Always responses with 429 response.
+ *   /retry-after					- 429 response with a Retry-After header, once.
+ *   /server-error				- 500 response that returns 200 after two attempts.
  *   /success							- 200 response with content body.
  *   /timeout							- Slow response.
  */
 func createTestServer() *httptest.Server {
 	mux := http.NewServeMux()
+	mux.HandleFunc("/echo",								echoHandler)
 	mux.HandleFunc("/empty", 							emptyHandler)
 	mux.HandleFunc("/failure",						failureHandler)
+	mux.HandleFunc("/items",							itemsHandler)
+	mux.HandleFunc("/items-unpaged",			unpagedItemsHandler)
 	mux.HandleFunc("/invalid-token",			invalidTokenHandler)
 	mux.HandleFunc("/malformed-failure",	malformedFailureHandler)
 	mux.HandleFunc("/malformed-success",	malformedSuccessHandler)
 	mux.HandleFunc("/no-access",					noAccessHandler)
 	mux.HandleFunc("/rate-limit-partial",	rateLimitPartialHandler)
 	mux.HandleFunc("/rate-limit-full",		rateLimitFullHandler)
+	mux.HandleFunc("/retry-after",				flakyHandler(1, retryAfterHandler))
+	mux.HandleFunc("/server-error",				flakyHandler(2, failureHandler))
 	mux.HandleFunc("/success",						successHandler)
 	mux.HandleFunc("/timeout",						timeoutHandler)
 
-	return httptest.NewServer(mux)
+	return httptest.NewServer(http.StripPrefix("/" + supported_version, mux))
 }
 
 
 // Value Listener ======================================================== //
 
 
+/* 
+ * Collects values sent over a channel.  Values are owned by the listener's
+ * goroutine and only ever handed out as copies, so that they may be safely
+ * read by tests while handlers continue to send.
+ */
 type valueListener struct {
 	channel  chan item
-	signal   chan bool
-	values   map[string]string
+	signal   chan chan map[string]string
 }
 
 func NewValueListener(channel chan item) valueListener {
 	listener := valueListener{
 		channel:  channel,
-		signal:   make(chan bool),
-		values:   make(map[string]string),
+		signal:   make(chan chan map[string]string),
 	}
 
 	go func() {
+		values := make(map[string]string)
 		for {
 			select {
 			case i := <- listener.channel:
-				listener.values[i.Key] = i.Value
-			case <- listener.signal:
-				listener.values = make(map[string]string)
+				values[i.Key] = i.Value
+			case reply := <- listener.signal:
+				reply <- values
+				values = make(map[string]string)
 			}
 		}
 	}()
@@ -144,13 +164,13 @@ func NewValueListener(channel chan item) valueListener {
 	return listener
 }
 
+/* 
+ * Returns the values collected so far and resets the listener.
+ */
 func (l *valueListener) Values() map[string]string {
-	m := make(map[string]string)
-	for k,v := range l.values {
-		m[k] = v
-	}
-	l.signal <- true
-	return m
+	reply := make(chan map[string]string)
+	l.signal <- reply
+	return <- reply
 }
 
 
@@ -167,6 +187,49 @@ func respond(res http.ResponseWriter, code int, content interface{}) {
 }
 
 
+/* 
+ * Writes a successful response containing the "id" querystring parameter of
+ * the request.
+ */
+func echoHandler (res http.ResponseWriter, req *http.Request) {
+	respond(res, http.StatusOK, "{ \"id\":\"" + req.URL.Query().Get("id") + "\" }")
+}
+
+
+/* 
+ * Writes the page of a list of 250 numbered items selected by the "size"
+ * and "from" querystring parameters.
+ */
+func itemsHandler (res http.ResponseWriter, req *http.Request) {
+	size, err := strconv.Atoi(req.URL.Query().Get("size"))
+	if err != nil {
+		size = 250
+	}
+	from, _ := strconv.Atoi(req.URL.Query().Get("from"))
+
+	page := []int{}
+	for i := from; i < from + size && i < 250; i++ {
+		page = append(page, i)
+	}
+	encoded, _ := json.Marshal(page)
+	respond(res, http.StatusOK, string(encoded))
+}
+
+
+/* 
+ * Writes the whole list of 250 numbered items, ignoring the "size" and
+ * "from" querystring parameters.
+ */
+func unpagedItemsHandler (res http.ResponseWriter, req *http.Request) {
+	page := []int{}
+	for i := 0; i < 250; i++ {
+		page = append(page, i)
+	}
+	encoded, _ := json.Marshal(page)
+	respond(res, http.StatusOK, string(encoded))
+}
+
+
 /* 
  * Writes a successful response with an empty string as the body content.
  */
@@ -223,6 +286,9 @@ func noAccessHandler (res http.ResponseWriter, req *http.Request) {
  * The pattern of two failed, one successful, will be repeated thereafter.
  */
 func rateLimitPartialHandler (res http.ResponseWriter, req *http.Request) {
+	failureMutex.Lock()
+	defer failureMutex.Unlock()
+
 	if failureCounter < 2 {
 		// Rate limit error
 		res.WriteHeader(429)
@@ -247,6 +313,42 @@ func rateLimitFullHandler (res http.ResponseWriter, req *http.Request) {
 }
 
 
+/* 
+ * Writes a rate-limiting response asking the client to wait one second.
+ */
+func retryAfterHandler (res http.ResponseWriter, req *http.Request) {
+	res.Header().Set("Retry-After", "1")
+	respond(res, 429, createCirconusError())
+}
+
+
+/* 
+ * Creates a handler which fails a number of times before writing a single
+ * successful response.  The pattern is repeated thereafter.
+ * 
+ * Arguments:
+ *		failures	Number of failures before each success.
+ *		fail			Handler used to write failed responses.
+ */
+func flakyHandler(failures int, fail http.HandlerFunc) http.HandlerFunc {
+	var mutex sync.Mutex
+	counter := 0
+
+	return func(res http.ResponseWriter, req *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if counter < failures {
+			counter += 1
+			fail(res, req)
+		} else {
+			counter = 0
+			respond(res, http.StatusOK, successJson)
+		}
+	}
+}
+
+
 /* 
  * Writes a successful response.
  */
@@ -284,10 +386,10 @@ func TestSuccess(t *testing.T) {
 		Method:			"GET",
 		Resource:		"/success",
 		Data:				"test data",
-		Parameters:	map[string]string { "vegetable":"carrot", "rock":"onyx" },
+		Parameters:	url.Values { "vegetable":{"carrot"}, "rock":{"onyx"} },
 	}
 
-	_, err := client.send(req)
+	_, err := client.send(context.Background(), req)
 	if err != nil {
 		t.Errorf("%s\n", err.Error())
 	} else {
@@ -308,7 +410,7 @@ func TestFailure(t *testing.T) {
 		Resource:		"/failure",
 	}
 
-	_, err := client.send(req)
+	_, err := client.send(context.Background(), req)
 	if err == nil {
 		t.Errorf("Client did not fail as expected\n")
 	} else {
@@ -331,7 +433,7 @@ func TestAccessDenied(t *testing.T) {
 		Resource:		"/no-access",
 	}
 
-	_, err := client.send(req)
+	_, err := client.send(context.Background(), req)
 	if err == nil {
 		t.Errorf("Client did not fail as expected\n")
 	} else {
@@ -350,7 +452,7 @@ func TestBadRequestData(t *testing.T) {
 		Data:				make(chan bool),
 	}
 
-	_, err := client.send(req)
+	_, err := client.send(context.Background(), req)
 	if err == nil {
 		t.Errorf("Client did not fail as expected\n")
 	} else {
@@ -368,7 +470,7 @@ func TestBadResource(t *testing.T) {
 		Resource:		"/nonexistent",
 	}
 
-	_, err := client.send(req)
+	_, err := client.send(context.Background(), req)
 	if err == nil {
 		t.Errorf("Client did not fail as expected\n")
 	} else {
@@ -386,7 +488,7 @@ func TestEmptyResponse(t *testing.T) {
 		Resource:		"/empty",
 	}
 
-	_, err := client.send(req)
+	_, err := client.send(context.Background(), req)
 	if err == nil {
 		t.Errorf("Client did not fail as expected\n")
 	} else {
@@ -404,7 +506,7 @@ func TestInvalidCredentials(t *testing.T) {
 		Resource:		"/invalid-token",
 	}
 
-	_, err := client.send(req)
+	_, err := client.send(context.Background(), req)
 	if err == nil {
 		t.Errorf("Client did not fail as expected\n")
 	} else {
@@ -422,7 +524,7 @@ func TestMalformedSuccess(t *testing.T) {
 		Resource:		"/malformed-success",
 	}
 
-	_, err := client.send(req)
+	_, err := client.send(context.Background(), req)
 	if err == nil {
 		t.Errorf("Client did not fail as expected\n")
 	} else {
@@ -441,7 +543,7 @@ func TestMalformedFailure(t *testing.T) {
 		Resource:		"/malformed-failure",
 	}
 
-	_, err := client.send(req)
+	_, err := client.send(context.Background(), req)
 	if err == nil {
 		t.Errorf("Client did not fail as expected\n")
 	} else {
@@ -461,7 +563,7 @@ func TestMalformedRequest(t *testing.T) {
 		Resource:		"/malformed",
 	}
 
-	_, err := client.send(req)
+	_, err := client.send(context.Background(), req)
 	if err == nil {
 		t.Errorf("Client did not fail as expected\n")
 	} else {
@@ -478,7 +580,7 @@ func TestTimout(t *testing.T) {
 		Resource:		"/timeout",
 	}
 
-	_, err := client.send(req)
+	_, err := client.send(context.Background(), req)
 	if err == nil {
 		t.Errorf("Client did not fail as expected\n")
 	} else {
@@ -496,13 +598,36 @@ func TestZeroTimout(t *testing.T) {
 		Resource:		"/timeout",
 	}
 
-	_, err := client.send(req)
+	_, err := client.send(context.Background(), req)
 	if err != nil {
 		t.Errorf("Client timed out despite having no timeout set\n")
 	}
 }
 
 
+func TestCancellation(t *testing.T) {
+	client := createClient(createTestServer())
+	client.Timeout = 0
+
+	req := request {
+		Method:			"GET",
+		Resource:		"/timeout",
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	_, err := client.send(ctx, req)
+	if err == nil {
+		t.Errorf("Client did not fail as expected\n")
+	} else {
+		t.Logf("%s\n", err.Error())
+		expect(t, reflect.TypeOf(err).Name(), "RequestCanceledError")
+		expect(t, errors.Is(err, context.DeadlineExceeded), true)
+	}
+}
+
+
 func TestFullRateLimit(t *testing.T) {
 	client := createClient(createTestServer())
 
@@ -511,7 +636,7 @@ func TestFullRateLimit(t *testing.T) {
 		Resource:		"/rate-limit-full",
 	}
 
-	_, err := client.send(req)
+	_, err := client.send(context.Background(), req)
 	if err == nil {
 		t.Errorf("Client did not fail as expected\n")
 	} else {
@@ -529,10 +654,325 @@ func TestPartialRateLimit(t *testing.T) {
 		Resource:		"/rate-limit-partial",
 	}
 
-	_, err := client.send(req)
+	_, err := client.send(context.Background(), req)
 	if err != nil {
 		t.Errorf("Client failed unexpectedly\n")
 	} else {
 		t.Logf("Client succeeded after retries\n")
 	}
 }
+
+
+func TestConcurrentRequests(t *testing.T) {
+	client := createClient(createTestServer())
+
+	const workers = 20
+	const requests = 25
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for r := 0; r < requests; r++ {
+				id := strconv.Itoa(worker) + "-" + strconv.Itoa(r)
+				req := request {
+					Method:			"GET",
+					Resource:		"/echo",
+					Parameters:	url.Values { "id":{id} },
+				}
+
+				res, err := client.send(context.Background(), req)
+				if err != nil {
+					t.Errorf("Request %s failed: %s\n", id, err.Error())
+					return
+				}
+				body, ok := res.(map[string]interface{})
+				if !ok || body["id"] != id {
+					t.Errorf("Request %s received another request's response: %v\n", id, res)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+}
+
+
+func TestRetryAfter(t *testing.T) {
+	client := createClient(createTestServer())
+
+	req := request {
+		Method:			"GET",
+		Resource:		"/retry-after",
+	}
+
+	start := time.Now()
+	_, err := client.send(context.Background(), req)
+	if err != nil {
+		t.Errorf("Client failed unexpectedly: %s\n", err.Error())
+	} else if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("Client retried after %s, ignoring Retry-After\n", elapsed)
+	}
+}
+
+
+func TestNoRetries(t *testing.T) {
+	client := createClient(createTestServer())
+	client.RetryPolicy = nil
+	client.Retries = 0
+
+	req := request {
+		Method:			"GET",
+		Resource:		"/retry-after",
+	}
+
+	start := time.Now()
+	_, err := client.send(context.Background(), req)
+	if err == nil {
+		t.Errorf("Client retried a request without being configured to\n")
+	} else {
+		expect(t, reflect.TypeOf(err).Name(), "RateLimitExceededError")
+	}
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("Client waited %s before failing, as if retrying\n", elapsed)
+	}
+}
+
+
+func TestServerErrorRetry(t *testing.T) {
+	client := createClient(createTestServer())
+
+	req := request {
+		Method:			"GET",
+		Resource:		"/server-error",
+	}
+
+	_, err := client.send(context.Background(), req)
+	if err == nil {
+		t.Errorf("Client retried a server error without being configured to\n")
+	}
+
+	client.RetryPolicy = ExponentialBackoff {
+		InitialInterval:		time.Duration(10) * time.Millisecond,
+		RetryServerErrors:	true,
+	}
+	_, err = client.send(context.Background(), req)
+	if err != nil {
+		t.Errorf("Client failed unexpectedly: %s\n", err.Error())
+	}
+
+	req.Method = "POST"
+	_, err = client.send(context.Background(), req)
+	if err == nil {
+		t.Errorf("Client retried a non-idempotent request\n")
+	}
+}
+
+
+func TestSharedRateLimit(t *testing.T) {
+	server := createTestServer()
+	first := createClient(server)
+	second := createClient(server)
+
+	// A token of its own, so other tests and repeated runs start afresh
+	first.token = "shared-rate-limit"
+	second.token = "shared-rate-limit"
+	defer first.ReleaseRateLimit()
+
+	limiter := first.SetRateLimit(20, 2)
+	if second.SetRateLimit(20, 2) != limiter {
+		t.Fatalf("Clients with the same token do not share a rate limiter\n")
+	}
+
+	req := request {
+		Method:			"GET",
+		Resource:		"/echo",
+	}
+
+	for _, client := range []*Client{ first, second, first, second } {
+		if _, err := client.send(context.Background(), req); err != nil {
+			t.Errorf("Client failed unexpectedly: %s\n", err.Error())
+		}
+	}
+
+	stats := limiter.Stats()
+	expect(t, stats.Requests, int64(4))
+	expect(t, stats.Delayed, int64(2))
+	if stats.TotalWait < time.Duration(50) * time.Millisecond {
+		t.Errorf("Requests waited %s, less than the rate allows\n", stats.TotalWait)
+	}
+
+	// Reconfiguring the limit resets its statistics
+	second.SetRateLimit(10, 1)
+	expect(t, limiter.Stats(), RateLimiterStats {})
+
+	// Removing the limit admits requests straight away, and a limit set
+	// afterwards is not owed the tokens they used
+	limiter.SetLimit(0, 1)
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait failed unexpectedly: %s\n", err.Error())
+		}
+	}
+	expect(t, limiter.Stats().Delayed, int64(0))
+	limiter.SetLimit(10, 1)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait failed unexpectedly: %s\n", err.Error())
+	}
+	if elapsed := time.Since(start); elapsed > time.Duration(50) * time.Millisecond {
+		t.Errorf("Requests waited %s after the limit was removed\n", elapsed)
+	}
+
+	// Released limiters are not shared with Clients setting limits later
+	first.ReleaseRateLimit()
+	expect(t, first.RateLimiter() == nil, true)
+	if second.SetRateLimit(20, 2) == limiter {
+		t.Errorf("Released rate limiter is still shared\n")
+	}
+	second.ReleaseRateLimit()
+}
+
+
+func TestOptions(t *testing.T) {
+	client, err := NewClient("sampleapp", "abc123",
+		WithHost("https://circonus.example.com/api/"),
+		WithAPIVersion("v3"),
+		WithUserAgent("test-agent"))
+	if err != nil {
+		t.Fatalf("Client failed unexpectedly: %s\n", err.Error())
+	}
+	expect(t, client.host, "https://circonus.example.com/api")
+	expect(t, client.path, "/v3")
+	expect(t, client.useragent, "test-agent")
+
+	for _, host := range []string{ "ftp://example.com", "https://", "https://example.com?q=1", "://" } {
+		if _, err := NewClient("sampleapp", "abc123", WithHost(host)); err == nil {
+			t.Errorf("Client accepted invalid host \"%s\"\n", host)
+		} else {
+			expect(t, reflect.TypeOf(err).Name(), "OptionError")
+		}
+	}
+}
+
+
+func TestListIterator(t *testing.T) {
+	client := createClient(createTestServer())
+
+	count := 0
+	it := client.ListIter(context.Background(), "/items", &ListOptions{ Size:100 })
+	for it.Next() {
+		expect(t, it.Item(), float64(count))
+		count += 1
+	}
+	if it.Err() != nil {
+		t.Errorf("Iterator failed unexpectedly: %s\n", it.Err().Error())
+	}
+	expect(t, count, 250)
+
+	// Servers ignoring the page requested return each item once
+	count = 0
+	it = client.ListIter(context.Background(), "/items-unpaged", &ListOptions{ Size:100 })
+	for it.Next() {
+		expect(t, it.Item(), float64(count))
+		count += 1
+	}
+	if it.Err() != nil {
+		t.Errorf("Iterator failed unexpectedly: %s\n", it.Err().Error())
+	}
+	expect(t, count, 250)
+}
+
+
+func TestQuery(t *testing.T) {
+	q := NewQuery().
+		Tag("env:prod", "role:web").
+		Search("apache").
+		Where("type", "httptrap", "json").
+		Wildcard("display_name", "web*").
+		Has("brokers", "/broker/1")
+
+	values := q.Values()
+	expect(t, values.Get("search"), "(tags:env:prod,role:web)apache")
+	expect(t, len(values["f_type"]), 2)
+	expect(t, values.Get("f_display_name_wildcard"), "web*")
+	expect(t, values.Get("f_brokers_has"), "/broker/1")
+
+	opts := &ListOptions{ Size:10, From:20, Query:q }
+	params := opts.parameters()
+	expect(t, params.Get("size"), "10")
+	expect(t, params.Get("from"), "20")
+	expect(t, params.Get("search"), values.Get("search"))
+}
+
+
+func TestParseCID(t *testing.T) {
+	tests := []struct {
+		kind			resource
+		input			string
+		expected	CID
+	}{
+		{ CHECK_BUNDLE,	"1234",																		"/check_bundle/1234" },
+		{ CHECK_BUNDLE,	"/check_bundle/1234",											"/check_bundle/1234" },
+		{ "",						"/check_bundle/1234",											"/check_bundle/1234" },
+		{ GRAPH,				"7a0b9ae6-1d4c-4b3e-8f10-2e5f5c1d2a3b",		"/graph/7a0b9ae6-1d4c-4b3e-8f10-2e5f5c1d2a3b" },
+		{ RULE_SET,			"1234_cpu_used",													"/rule_set/1234_cpu_used" },
+		{ USER,					"current",																"/user/current" },
+		{ CHECK_BUNDLE,	"abc",																		"" },
+		{ CHECK_BUNDLE,	"/graph/1234",														"" },
+		{ GRAPH,				"1234",																		"" },
+		{ "",						"1234",																		"" },
+		{ CHECK,				"/check/",																"" },
+	}
+
+	for _, test := range tests {
+		cid, err := ParseCID(test.kind, test.input)
+		if test.expected == "" {
+			if err == nil {
+				t.Errorf("Parsed invalid %s CID \"%s\" as \"%s\"\n", test.kind, test.input, cid)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Failed to parse %s CID \"%s\": %s\n", test.kind, test.input, err.Error())
+		}
+		expect(t, cid, test.expected)
+	}
+
+	expect(t, CID("/check_bundle/1234").Resource(), CHECK_BUNDLE)
+	expect(t, CID("/check_bundle/1234").ID(), "1234")
+}
+
+
+func TestErrorDetails(t *testing.T) {
+	client := createClient(createTestServer())
+
+	req := request {
+		Method:			"GET",
+		Resource:		"/failure",
+	}
+
+	_, err := client.send(context.Background(), req)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Error does not wrap an APIError: %v\n", err)
+	}
+	expect(t, apiErr.StatusCode, http.StatusInternalServerError)
+	expect(t, apiErr.Method, "GET")
+	expect(t, apiErr.Circonus.Code, "1234")
+	expect(t, errors.Is(err, ErrServer), true)
+	expect(t, errors.Is(err, ErrNotFound), false)
+
+	req.Resource = "/nonexistent"
+	_, err = client.send(context.Background(), req)
+	expect(t, errors.Is(err, ErrNotFound), true)
+	expect(t, reflect.TypeOf(err).Name(), "ResourceNotFoundError")
+
+	req.Resource = "/rate-limit-full"
+	_, err = client.send(context.Background(), req)
+	expect(t, errors.Is(err, ErrRateLimitExceeded), true)
+	expect(t, errors.Is(err, ErrRateLimited), true)
+
+	expect(t, errors.Is(AccessDeniedError{}, ErrAccessDenied), true)
+}
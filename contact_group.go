@@ -0,0 +1,231 @@
+package circonus
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Structures ============================================================ //
+
+// A ContactGroup is a set of people and services notified of alerts.
+//
+// Escalations and Reminders are indexed by severity, such that the first
+// entry of each applies to severity one alerts.  The helper methods of
+// ContactGroup take care of this indexing.
+type ContactGroup struct {
+	CID               CID                       `json:"_cid,omitempty"`
+	LastModified      int64                     `json:"_last_modified,omitempty"`
+	LastModifiedBy    string                    `json:"_last_modified_by,omitempty"`
+	AggregationWindow int                       `json:"aggregation_window"` // Seconds
+	AlertFormats      ContactGroupAlertFormats  `json:"alert_formats"`
+	Contacts          ContactGroupContacts      `json:"contacts"`
+	Escalations       []*ContactGroupEscalation `json:"escalations"`
+	Name              string                    `json:"name"`
+	Reminders         []int                     `json:"reminders"` // Seconds
+	Tags              []string                  `json:"tags"`
+}
+
+// Templates for the content of notifications.  Nil values use Circonus'
+// default formats.
+type ContactGroupAlertFormats struct {
+	LongMessage  *string `json:"long_message"`
+	LongSubject  *string `json:"long_subject"`
+	LongSummary  *string `json:"long_summary"`
+	ShortMessage *string `json:"short_message"`
+	ShortSummary *string `json:"short_summary"`
+}
+
+// The recipients of a contact group's notifications.
+type ContactGroupContacts struct {
+	External []ContactGroupExternal `json:"external"`
+	Users    []ContactGroupUser     `json:"users"`
+}
+
+// A contact outside of Circonus' user accounts, such as an email address or
+// an integration with another service.
+type ContactGroupExternal struct {
+	Info   string `json:"contact_info"`
+	Method string `json:"method"`
+}
+
+// A Circonus user, notified through one of their configured methods.
+type ContactGroupUser struct {
+	Info    string `json:"_contact_info,omitempty"`
+	Method  string `json:"method"`
+	UserCID CID    `json:"user"`
+}
+
+// Moves unacknowledged alerts to another contact group after a delay.
+type ContactGroupEscalation struct {
+	After           int `json:"after"` // Seconds
+	ContactGroupCID CID `json:"contact_group"`
+}
+
+// Settings for notifying a Slack channel.
+type SlackContact struct {
+	Buttons  bool   `json:"buttons"`
+	Channel  string `json:"channel"`
+	Team     string `json:"team"`
+	Username string `json:"username"`
+}
+
+// Settings for notifying a PagerDuty service.
+type PagerDutyContact struct {
+	Account    string `json:"account"`
+	ServiceKey string `json:"service_key"`
+	WebhookURL string `json:"webhook_url"`
+}
+
+// Constants & Data ====================================================== //
+
+// Notification methods.
+const (
+	CONTACT_EMAIL     string = "email"
+	CONTACT_PAGERDUTY string = "pagerduty"
+	CONTACT_SLACK     string = "slack"
+	CONTACT_SMS       string = "sms"
+	CONTACT_WEBHOOK   string = "http"
+	CONTACT_XMPP      string = "xmpp"
+)
+
+const default_aggregation_window int = 300
+
+// Contact Group Helpers ================================================= //
+
+// Creates an empty ContactGroup with Circonus' default aggregation window.
+func NewContactGroup(name string) *ContactGroup {
+	return &ContactGroup{
+		AggregationWindow: default_aggregation_window,
+		Contacts: ContactGroupContacts{
+			External: []ContactGroupExternal{},
+			Users:    []ContactGroupUser{},
+		},
+		Escalations: make([]*ContactGroupEscalation, max_severity),
+		Name:        name,
+		Reminders:   make([]int, max_severity),
+		Tags:        []string{},
+	}
+}
+
+// Adds an email address to notify.
+func (cg *ContactGroup) AddEmail(address string) {
+	cg.addExternal(CONTACT_EMAIL, address)
+}
+
+// Adds a phone number to notify by SMS.
+func (cg *ContactGroup) AddSMS(number string) {
+	cg.addExternal(CONTACT_SMS, number)
+}
+
+// Adds a URL to which notifications are posted.
+func (cg *ContactGroup) AddWebhook(url string) {
+	cg.addExternal(CONTACT_WEBHOOK, url)
+}
+
+// Adds a Slack channel to notify.
+func (cg *ContactGroup) AddSlack(s SlackContact) error {
+	return cg.addExternalJSON(CONTACT_SLACK, s)
+}
+
+// Adds a PagerDuty service to notify.
+func (cg *ContactGroup) AddPagerDuty(pd PagerDutyContact) error {
+	return cg.addExternalJSON(CONTACT_PAGERDUTY, pd)
+}
+
+// Adds a Circonus user, notified by the given method.
+func (cg *ContactGroup) AddUser(userCID CID, method string) {
+	cg.Contacts.Users = append(cg.Contacts.Users, ContactGroupUser{
+		Method:  method,
+		UserCID: userCID,
+	})
+}
+
+// Escalates alerts of a severity to another contact group if they remain
+// unacknowledged after the given delay.
+func (cg *ContactGroup) Escalate(severity int, after time.Duration, contactGroupCID CID) error {
+	if err := checkSeverity(severity); err != nil {
+		return err
+	}
+	for len(cg.Escalations) < max_severity {
+		cg.Escalations = append(cg.Escalations, nil)
+	}
+	cg.Escalations[severity-1] = &ContactGroupEscalation{
+		After:           int(after / time.Second),
+		ContactGroupCID: contactGroupCID,
+	}
+	return nil
+}
+
+// Repeats notifications of alerts of a severity at the given interval until
+// they are cleared or acknowledged.  An interval of zero disables reminders.
+func (cg *ContactGroup) Remind(severity int, every time.Duration) error {
+	if err := checkSeverity(severity); err != nil {
+		return err
+	}
+	for len(cg.Reminders) < max_severity {
+		cg.Reminders = append(cg.Reminders, 0)
+	}
+	cg.Reminders[severity-1] = int(every / time.Second)
+	return nil
+}
+
+func (cg *ContactGroup) addExternal(method string, info string) {
+	cg.Contacts.External = append(cg.Contacts.External, ContactGroupExternal{
+		Info:   info,
+		Method: method,
+	})
+}
+
+// Adds an external contact whose settings Circonus expects as a JSON string.
+func (cg *ContactGroup) addExternalJSON(method string, settings interface{}) error {
+	encoded, err := json.Marshal(settings)
+	if err != nil {
+		return RequestDataError{Reason: err.Error()}
+	}
+	cg.addExternal(method, string(encoded))
+	return nil
+}
+
+// Returns a ValidationError if a severity is out of range.
+func checkSeverity(severity int) error {
+	if severity < min_severity || severity > max_severity {
+		return ValidationError{
+			Resource: string(CONTACT_GROUP),
+			Problems: []string{"severity must be between 1 and 5"},
+		}
+	}
+	return nil
+}
+
+// Contact Group API ===================================================== //
+
+// Creates a new contact group and returns it as stored by Circonus.
+func (c *Client) CreateContactGroup(ctx context.Context, cg *ContactGroup) (*ContactGroup, error) {
+	return pointerTo(NewResource[ContactGroup](c, CONTACT_GROUP).Create(ctx, *cg))
+}
+
+// Retrieves the contact group with the given CID.
+func (c *Client) GetContactGroup(ctx context.Context, cid CID) (*ContactGroup, error) {
+	return pointerTo(NewResource[ContactGroup](c, CONTACT_GROUP).Get(ctx, cid))
+}
+
+// Replaces an existing contact group, identified by its CID, and returns the
+// updated contact group.
+func (c *Client) UpdateContactGroup(ctx context.Context, cg *ContactGroup) (*ContactGroup, error) {
+	if cg.CID == "" {
+		return nil, RequestDataError{Reason: "contact group has no CID"}
+	}
+	return pointerTo(NewResource[ContactGroup](c, CONTACT_GROUP).Update(ctx, cg.CID, *cg))
+}
+
+// Deletes the contact group with the given CID.
+func (c *Client) DeleteContactGroup(ctx context.Context, cid CID) error {
+	return NewResource[ContactGroup](c, CONTACT_GROUP).Delete(ctx, cid)
+}
+
+// Retrieves every contact group visible to the Client's access token,
+// optionally filtered and paged by the given options.
+func (c *Client) ListContactGroups(ctx context.Context, opts *ListOptions) ([]ContactGroup, error) {
+	return NewResource[ContactGroup](c, CONTACT_GROUP).List(ctx, opts)
+}
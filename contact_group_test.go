@@ -0,0 +1,87 @@
+package circonus
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+
+func TestContactGroupContacts(t *testing.T) {
+	cg := NewContactGroup("ops")
+	cg.AddEmail("ops@example.com")
+	cg.AddWebhook("https://hooks.example.com/alerts")
+	if err := cg.AddSlack(SlackContact{ Buttons:true, Channel:"#alerts", Team:"T123", Username:"circonus" }); err != nil {
+		t.Fatalf("AddSlack failed unexpectedly: %s\n", err.Error())
+	}
+	if err := cg.AddPagerDuty(PagerDutyContact{ Account:"example", ServiceKey:"key", WebhookURL:"https://events.pagerduty.com" }); err != nil {
+		t.Fatalf("AddPagerDuty failed unexpectedly: %s\n", err.Error())
+	}
+	cg.AddUser("/user/7", CONTACT_SMS)
+
+	encoded, _ := json.Marshal(cg)
+	var body struct {
+		Contacts struct {
+			External	[]map[string]string	`json:"external"`
+			Users			[]map[string]string	`json:"users"`
+		} `json:"contacts"`
+	}
+	json.Unmarshal(encoded, &body)
+
+	external := body.Contacts.External
+	expect(t, len(external), 4)
+	expect(t, external[0]["method"], "email")
+	expect(t, external[0]["contact_info"], "ops@example.com")
+	expect(t, external[1]["method"], "http")
+	expect(t, external[1]["contact_info"], "https://hooks.example.com/alerts")
+	expect(t, external[2]["method"], "slack")
+	expect(t, external[2]["contact_info"], `{"buttons":true,"channel":"#alerts","team":"T123","username":"circonus"}`)
+	expect(t, external[3]["method"], "pagerduty")
+	expect(t, external[3]["contact_info"], `{"account":"example","service_key":"key","webhook_url":"https://events.pagerduty.com"}`)
+
+	expect(t, len(body.Contacts.Users), 1)
+	expect(t, body.Contacts.Users[0]["user"], "/user/7")
+	expect(t, body.Contacts.Users[0]["method"], "sms")
+}
+
+
+func TestContactGroupSeverities(t *testing.T) {
+	cg := NewContactGroup("ops")
+	if err := cg.Escalate(2, time.Duration(15) * time.Minute, "/contact_group/9"); err != nil {
+		t.Fatalf("Escalate failed unexpectedly: %s\n", err.Error())
+	}
+	if err := cg.Remind(5, time.Hour); err != nil {
+		t.Fatalf("Remind failed unexpectedly: %s\n", err.Error())
+	}
+	if err := cg.Remind(1, time.Duration(10) * time.Minute); err != nil {
+		t.Fatalf("Remind failed unexpectedly: %s\n", err.Error())
+	}
+
+	encoded, _ := json.Marshal(cg)
+	var body map[string]json.RawMessage
+	json.Unmarshal(encoded, &body)
+	expect(t, string(body["escalations"]), `[null,{"after":900,"contact_group":"/contact_group/9"},null,null,null]`)
+	expect(t, string(body["reminders"]), `[600,0,0,0,3600]`)
+
+	// Groups decoded with short lists are extended as needed
+	decoded := &ContactGroup{}
+	json.Unmarshal([]byte(`{ "escalations":[], "reminders":[60] }`), decoded)
+	decoded.Escalate(4, time.Minute, "/contact_group/3")
+	decoded.Remind(3, time.Minute)
+	expect(t, len(decoded.Escalations), 5)
+	expect(t, decoded.Escalations[3].ContactGroupCID, CID("/contact_group/3"))
+	expect(t, decoded.Reminders[0], 60)
+	expect(t, decoded.Reminders[2], 60)
+
+	for _, severity := range []int{ 0, 6, -1 } {
+		if err := cg.Escalate(severity, time.Minute, "/contact_group/9"); !errors.Is(err, ErrValidation) {
+			t.Errorf("Escalate accepted severity %d\n", severity)
+		}
+		if err := cg.Remind(severity, time.Minute); !errors.Is(err, ErrValidation) {
+			t.Errorf("Remind accepted severity %d\n", severity)
+		}
+	}
+	expect(t, len(cg.Escalations), 5)
+	expect(t, len(cg.Reminders), 5)
+}
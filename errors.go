@@ -1,12 +1,124 @@
 package circonus
 
+import (
+  "errors"
+  "net/http"
+  "strconv"
+  "strings"
+  "time"
+)
 
-type AccessDeniedError struct {}
+// Sentinel errors, for use with errors.Is.  Errors resulting from a response
+// by Circonus match the sentinel for the response's status code, in addition
+// to the sentinel for their own type.
+var (
+  ErrAccessDenied       = errors.New("access denied")
+  ErrBadRequest         = errors.New("bad request")
+  ErrCAQL               = errors.New("invalid CAQL query")
+  ErrConflict           = errors.New("conflict")
+  ErrDuplicateBundles   = errors.New("duplicate check bundles")
+  ErrEmptyResponse      = errors.New("empty response")
+  ErrInvalidCID         = errors.New("invalid CID")
+  ErrMalformedResponse  = errors.New("malformed response")
+  ErrMetricsDropped     = errors.New("metrics dropped")
+  ErrNotFound           = errors.New("not found")
+  ErrRateLimited        = errors.New("rate limited")
+  ErrRateLimitExceeded  = errors.New("rate limit exceeded")
+  ErrRequestData        = errors.New("invalid request data")
+  ErrServer             = errors.New("server error")
+  ErrTokenNotValidated  = errors.New("token not validated")
+  ErrUnsupportedMethod  = errors.New("unsupported method")
+  ErrValidation         = errors.New("validation failed")
+)
+
+// An APIError describes a failed exchange with Circonus: the request made,
+// and the response received.
+//
+// APIErrors are not returned directly, but are wrapped by the more specific
+// error types below, from which they may be retrieved with errors.As.
+type APIError struct {
+  StatusCode int
+  Method     string
+  Endpoint   string         // URL of the request
+  Body       []byte         // Raw body of the response
+  Circonus   *CirconusError // Error details given by Circonus, if any
+  RequestID  string         // Value of the X-Request-Id response header
+  Err        error          // Underlying cause, such as a decoding error
+}
+
+func (e *APIError) Error() string {
+  message := e.Method + " " + e.Endpoint + ": " + strconv.Itoa(e.StatusCode) + " " + http.StatusText(e.StatusCode)
+  if e.Circonus != nil && e.Circonus.Explanation != "" {
+    message += ": " + e.Circonus.Explanation
+  } else if e.Err != nil {
+    message += ": " + e.Err.Error()
+  }
+  return message
+}
+
+// Reports whether the error matches the sentinel for its status code.
+func (e *APIError) Is(target error) bool {
+  switch {
+  case e.StatusCode == 400:
+    return target == ErrBadRequest
+  case e.StatusCode == 401:
+    return target == ErrTokenNotValidated
+  case e.StatusCode == 403:
+    return target == ErrAccessDenied
+  case e.StatusCode == 404:
+    return target == ErrNotFound
+  case e.StatusCode == 409:
+    return target == ErrConflict
+  case e.StatusCode == 429:
+    return target == ErrRateLimited
+  case e.StatusCode >= 500:
+    return target == ErrServer
+  }
+  return false
+}
+
+func (e *APIError) Unwrap() error {
+  return e.Err
+}
+
+// Returns the errors wrapped by one of the types below: its sentinel, and
+// the APIError it was created from, if any.
+func wrapped(sentinel error, api *APIError) []error {
+  if api == nil {
+    return []error{sentinel}
+  }
+  return []error{sentinel, api}
+}
+
+type AccessDeniedError struct {
+  api *APIError
+}
 
 func (e AccessDeniedError) Error() string {
+  if e.api != nil && e.api.Circonus != nil && e.api.Circonus.Explanation != "" {
+    return "Access denied: " + e.api.Circonus.Explanation
+  }
   return "Access denied"
 }
 
+func (e AccessDeniedError) Unwrap() []error {
+  return wrapped(ErrAccessDenied, e.api)
+}
+
+type CAQLError struct {
+  Query   string
+  Message string  // Explanation given by Circonus
+  api     *APIError
+}
+
+func (e CAQLError) Error() string {
+  return "Invalid CAQL query \"" + e.Query + "\": " + e.Message
+}
+
+func (e CAQLError) Unwrap() []error {
+  return wrapped(ErrCAQL, e.api)
+}
+
 type CirconusError struct {
   Code        string `json:"code"`
   Explanation string `json:"explanation"`
@@ -14,56 +126,189 @@ type CirconusError struct {
   Reference   string `json:"reference"`
   Tag         string `json:"tag"`
   Server      string `json:"server"`
+  api         *APIError
 }
 
 func (e CirconusError) Error() string {
   return e.Explanation
 }
 
-type EmptyResponseError struct{}
+func (e CirconusError) Unwrap() error {
+  if e.api == nil {
+    return nil
+  }
+  return e.api
+}
+
+type DuplicateCheckBundlesError struct {
+  Kept       CID    // Bundle returned, having the lowest CID
+  Duplicates []CID  // Other bundles matching the same spec
+}
+
+func (e DuplicateCheckBundlesError) Error() string {
+  return "Check bundle " + string(e.Kept) + " has " + strconv.Itoa(len(e.Duplicates)) + " duplicates"
+}
+
+func (e DuplicateCheckBundlesError) Unwrap() error {
+  return ErrDuplicateBundles
+}
+
+type EmptyResponseError struct {
+  api *APIError
+}
 
 func (e EmptyResponseError) Error() string {
   return "Empty response from Circonus"
 }
 
+func (e EmptyResponseError) Unwrap() []error {
+  return wrapped(ErrEmptyResponse, e.api)
+}
+
+type InvalidCIDError struct {
+  CID    string
+  Reason string
+}
+
+func (e InvalidCIDError) Error() string {
+  return "Invalid CID \"" + e.CID + "\": " + e.Reason
+}
+
+func (e InvalidCIDError) Unwrap() error {
+  return ErrInvalidCID
+}
+
 type MalformedResponseError struct {
   Reason string
+  api    *APIError
 }
 
 func (e MalformedResponseError) Error() string {
-  return "Malformed JSON response from Circonus"
+  return "Malformed JSON response from Circonus: " + e.Reason
+}
+
+func (e MalformedResponseError) Unwrap() []error {
+  return wrapped(ErrMalformedResponse, e.api)
+}
+
+type MetricsDroppedError struct {
+  Dropped int    // Number of metrics discarded
+  Err     error  // Error of the last failed submission
+}
+
+func (e MetricsDroppedError) Error() string {
+  return "Dropped " + strconv.Itoa(e.Dropped) + " metrics after repeated failed submissions: " + e.Err.Error()
 }
 
-type RateLimitError struct {}
+func (e MetricsDroppedError) Unwrap() []error {
+  return []error{ErrMetricsDropped, e.Err}
+}
+
+type OptionError struct {
+  Option string
+  Reason string
+}
+
+func (e OptionError) Error() string {
+  return "Invalid client option " + e.Option + ": " + e.Reason
+}
+
+type RateLimitError struct {
+  RetryAfter time.Duration  // Delay requested by Circonus, if any
+  api        *APIError
+}
 
 func (e RateLimitError) Error() string {
   return "Request was rate limited"
 }
 
-type RateLimitExceededError struct {}
+func (e RateLimitError) Unwrap() []error {
+  return wrapped(ErrRateLimited, e.api)
+}
+
+type RateLimitExceededError struct {
+  api *APIError  // Response to the final attempt
+}
 
 func (e RateLimitExceededError) Error() string {
   return "Request exceeded rate limit and exhausted retries"
 }
 
+func (e RateLimitExceededError) Unwrap() []error {
+  return wrapped(ErrRateLimitExceeded, e.api)
+}
+
+type RequestCanceledError struct {
+  Err error  // Either context.Canceled or context.DeadlineExceeded
+}
+
+func (e RequestCanceledError) Error() string {
+  return "Request canceled: " + e.Err.Error()
+}
+
+func (e RequestCanceledError) Unwrap() error {
+  return e.Err
+}
+
 type RequestDataError struct {
   Reason string
 }
 
 func (e RequestDataError) Error() string {
-  return "Cannot encode request data"
+  return "Cannot encode request data: " + e.Reason
+}
+
+func (e RequestDataError) Unwrap() error {
+  return ErrRequestData
 }
 
 type ResourceNotFoundError struct {
   Endpoint string
+  api      *APIError
 }
 
 func (e ResourceNotFoundError) Error() string {
   return "Circonus endpoint \"" + e.Endpoint + "\" not found"
 }
 
-type TokenNotValidatedError struct {}
+func (e ResourceNotFoundError) Unwrap() []error {
+  return wrapped(ErrNotFound, e.api)
+}
+
+type TokenNotValidatedError struct {
+  api *APIError
+}
 
 func (e TokenNotValidatedError) Error() string {
   return "Invalid authentication token"
 }
+
+func (e TokenNotValidatedError) Unwrap() []error {
+  return wrapped(ErrTokenNotValidated, e.api)
+}
+
+type UnsupportedMethodError struct {
+  Method   string
+  Resource string
+}
+
+func (e UnsupportedMethodError) Error() string {
+  return "Circonus does not support " + e.Method + " requests to \"" + e.Resource + "\""
+}
+
+func (e UnsupportedMethodError) Unwrap() error {
+  return ErrUnsupportedMethod
+}
+
+type ValidationError struct {
+  Resource string
+  Problems []string
+}
+
+func (e ValidationError) Error() string {
+  return "Invalid " + e.Resource + ": " + strings.Join(e.Problems, "; ")
+}
+
+func (e ValidationError) Unwrap() error {
+  return ErrValidation
+}
@@ -0,0 +1,304 @@
+package circonus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Structures ============================================================ //
+
+// A FetchRequest selects metric data to retrieve with FetchData: Count
+// periods of Period length, beginning at Start, from each of Streams.
+//
+// Streams are combined according to Reduce.  If no reductions are given,
+// each stream is returned as a series of its own.
+type FetchRequest struct {
+	Start   time.Time
+	Period  time.Duration // Whole number of seconds
+	Count   int
+	Streams []FetchStream
+	Reduce  []FetchReduce
+}
+
+// A FetchStream identifies a single metric of a check, and how its data is
+// summarized over each period.
+type FetchStream struct {
+	CheckUUID       string   `json:"uuid"`
+	Name            string   `json:"name"`
+	Kind            string   `json:"kind"`             // One of the FETCH_KIND_* constants
+	Label           string   `json:"label,omitempty"`  // Name of the stream in the result
+	Transform       string   `json:"transform"`        // One of the FETCH_TRANSFORM_* constants
+	TransformParams []string `json:"transform_params"` // Arguments to the transform, if any
+}
+
+// A FetchReduce combines the streams of a FetchRequest into a single
+// series, or passes each through as its own series.
+type FetchReduce struct {
+	Label        string   `json:"label"`
+	Method       string   `json:"method"` // One of the FETCH_REDUCE_* constants
+	MethodParams []string `json:"method_params"`
+}
+
+// A FetchResult holds the data retrieved by FetchData, as one series for
+// each stream or reduction.
+type FetchResult struct {
+	Start  time.Time
+	Period time.Duration
+	Count  int
+	Series []FetchSeries
+}
+
+// A FetchSeries holds a value for each period of a FetchResult.
+type FetchSeries struct {
+	Label  string
+	Kind   string
+	Points []FetchPoint
+}
+
+// A FetchPoint holds the value of a series for a single period.  Only the
+// field matching the series' kind is set, and then only if data exists for
+// the period.
+type FetchPoint struct {
+	Timestamp time.Time
+	Value     *float64
+	Text      *string
+	Histogram *Histogram
+}
+
+// Internal types for encoding requests and decoding responses.
+type fetchBody struct {
+	Start   int64         `json:"start"`
+	Period  int64         `json:"period"`
+	Count   int           `json:"count"`
+	Streams []FetchStream `json:"streams"`
+	Reduce  []FetchReduce `json:"reduce"`
+}
+
+type fetchResponse struct {
+	Head struct {
+		Start  int64 `json:"start"`
+		Period int64 `json:"period"`
+		Count  int   `json:"count"`
+	} `json:"head"`
+	Meta []struct {
+		Label string `json:"label"`
+		Kind  string `json:"kind"`
+	} `json:"meta"`
+	Data [][]json.RawMessage `json:"data"`
+}
+
+// Constants & Data ====================================================== //
+
+// Kinds of metric stream.
+const (
+	FETCH_KIND_HISTOGRAM string = "histogram"
+	FETCH_KIND_NUMERIC   string = "numeric"
+	FETCH_KIND_TEXT      string = "text"
+)
+
+// Summaries of a numeric stream over each period.  Text and histogram
+// streams are not summarized, and use FETCH_TRANSFORM_NONE.
+const (
+	FETCH_TRANSFORM_AVERAGE string = "average" // Mean value
+	FETCH_TRANSFORM_COUNT   string = "count"   // Number of samples
+	FETCH_TRANSFORM_COUNTER string = "counter" // Rate of increase, ignoring decreases
+	FETCH_TRANSFORM_DERIVE  string = "derive"  // Rate of change
+	FETCH_TRANSFORM_NONE    string = "none"
+	FETCH_TRANSFORM_STDDEV  string = "stddev" // Standard deviation
+)
+
+// Methods of combining streams.
+const (
+	FETCH_REDUCE_MAX   string = "max"
+	FETCH_REDUCE_MEAN  string = "mean"
+	FETCH_REDUCE_MERGE string = "merge" // Histogram streams only
+	FETCH_REDUCE_MIN   string = "min"
+	FETCH_REDUCE_PASS  string = "pass" // Each stream is a series of its own
+	FETCH_REDUCE_SUM   string = "sum"
+)
+
+var fetchTransforms = map[string][]string{
+	FETCH_KIND_HISTOGRAM: {FETCH_TRANSFORM_NONE},
+	FETCH_KIND_NUMERIC: {
+		FETCH_TRANSFORM_AVERAGE,
+		FETCH_TRANSFORM_COUNT,
+		FETCH_TRANSFORM_COUNTER,
+		FETCH_TRANSFORM_DERIVE,
+		FETCH_TRANSFORM_STDDEV,
+	},
+	FETCH_KIND_TEXT: {FETCH_TRANSFORM_NONE},
+}
+
+// Fetch API ============================================================= //
+
+// Retrieves metric data as selected by a FetchRequest.  Requests are
+// validated before they are sent.
+func (c *Client) FetchData(ctx context.Context, fr FetchRequest) (*FetchResult, error) {
+	var response fetchResponse
+	req := request{
+		Method:   http.MethodPost,
+		Resource: FETCH.path(),
+		Data:     fr,
+		Result:   &response,
+	}
+	if _, err := c.send(ctx, req); err != nil {
+		return nil, err
+	}
+	return response.result(fr)
+}
+
+// Checks that a FetchRequest selects a valid window and valid streams.
+func (fr FetchRequest) Validate() error {
+	var problems []string
+
+	if fr.Start.IsZero() {
+		problems = append(problems, "no start time specified")
+	}
+	if fr.Period < time.Second || fr.Period%time.Second != 0 {
+		problems = append(problems, "period must be a whole number of seconds")
+	}
+	if fr.Count < 1 {
+		problems = append(problems, "count must be at least one")
+	}
+	if len(fr.Streams) == 0 {
+		problems = append(problems, "no streams specified")
+	}
+
+	for i, stream := range fr.Streams {
+		prefix := "stream " + strconv.Itoa(i+1) + ": "
+		if stream.CheckUUID == "" || stream.Name == "" {
+			problems = append(problems, prefix+"no check UUID or metric name specified")
+		}
+		transforms, known := fetchTransforms[stream.Kind]
+		if !known {
+			problems = append(problems, prefix+"unknown kind \""+stream.Kind+"\"")
+		} else if stream.Transform != "" && !contains(transforms, stream.Transform) {
+			problems = append(problems, prefix+"transform \""+stream.Transform+"\" cannot be applied to "+stream.Kind+" streams")
+		}
+	}
+
+	for i, reduce := range fr.Reduce {
+		if reduce.Method == "" {
+			problems = append(problems, "reduction "+strconv.Itoa(i+1)+": no method specified")
+		}
+	}
+
+	if len(problems) > 0 {
+		return ValidationError{Resource: string(FETCH), Problems: problems}
+	}
+	return nil
+}
+
+func (fr FetchRequest) MarshalJSON() ([]byte, error) {
+	body := fetchBody{
+		Start:   fr.Start.Unix(),
+		Period:  int64(fr.Period / time.Second),
+		Count:   fr.Count,
+		Streams: make([]FetchStream, len(fr.Streams)),
+		Reduce:  append([]FetchReduce{}, fr.Reduce...),
+	}
+
+	for i, stream := range fr.Streams {
+		if stream.Transform == "" {
+			stream.Transform = FETCH_TRANSFORM_NONE
+			if stream.Kind == FETCH_KIND_NUMERIC {
+				stream.Transform = FETCH_TRANSFORM_AVERAGE
+			}
+		}
+		if stream.TransformParams == nil {
+			stream.TransformParams = []string{}
+		}
+		body.Streams[i] = stream
+	}
+
+	if len(body.Reduce) == 0 {
+		body.Reduce = []FetchReduce{{Method: FETCH_REDUCE_PASS}}
+	}
+	for i := range body.Reduce {
+		if body.Reduce[i].MethodParams == nil {
+			body.Reduce[i].MethodParams = []string{}
+		}
+	}
+
+	return json.Marshal(body)
+}
+
+// Fetch Results ========================================================= //
+
+// Converts a response into a FetchResult.  Series are described by the
+// response's metadata where given, and otherwise by the request's streams.
+func (r fetchResponse) result(fr FetchRequest) (*FetchResult, error) {
+	result := &FetchResult{
+		Start:  time.Unix(r.Head.Start, 0),
+		Period: time.Duration(r.Head.Period) * time.Second,
+		Count:  r.Head.Count,
+		Series: make([]FetchSeries, len(r.Data)),
+	}
+
+	for i, data := range r.Data {
+		series := &result.Series[i]
+		switch {
+		case i < len(r.Meta):
+			series.Label, series.Kind = r.Meta[i].Label, r.Meta[i].Kind
+		case i < len(fr.Streams):
+			series.Label, series.Kind = fr.Streams[i].Label, fr.Streams[i].Kind
+		}
+
+		series.Points = make([]FetchPoint, len(data))
+		for j, raw := range data {
+			point, err := decodePoint(series.Kind, raw)
+			if err != nil {
+				return nil, MalformedResponseError{Reason: "series " + strconv.Itoa(i+1) + ": " + err.Error()}
+			}
+			point.Timestamp = result.Start.Add(time.Duration(j) * result.Period)
+			series.Points[j] = point
+		}
+	}
+
+	return result, nil
+}
+
+// Decodes the value of a single period.  Histograms may be given either as
+// bins in the form "H[1.2e+01]=3", or as an object of counts by bin value.
+func decodePoint(kind string, raw json.RawMessage) (FetchPoint, error) {
+	var point FetchPoint
+	if string(raw) == "null" {
+		return point, nil
+	}
+
+	switch kind {
+	case FETCH_KIND_TEXT:
+		var text string
+		err := json.Unmarshal(raw, &text)
+		point.Text = &text
+		return point, err
+
+	case FETCH_KIND_HISTOGRAM:
+		var counts map[string]uint64
+		if err := json.Unmarshal(raw, &counts); err == nil {
+			h := NewHistogram()
+			for bin, count := range counts {
+				value, err := strconv.ParseFloat(bin, 64)
+				if err != nil {
+					return point, err
+				}
+				h.RecordN(value, count)
+			}
+			point.Histogram = h
+			return point, nil
+		}
+		h := NewHistogram()
+		err := json.Unmarshal(raw, h)
+		point.Histogram = h
+		return point, err
+
+	default:
+		var value float64
+		err := json.Unmarshal(raw, &value)
+		point.Value = &value
+		return point, err
+	}
+}
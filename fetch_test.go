@@ -0,0 +1,92 @@
+package circonus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+
+func TestFetchData(t *testing.T) {
+	requests := make(chan recorded, 1)
+	client := createClient(createRecordingServer(requests))
+	ctx := context.Background()
+
+	fr := FetchRequest {
+		Start:		time.Unix(1500000000, 0),
+		Period:		time.Duration(5) * time.Minute,
+		Count:		3,
+		Streams:	[]FetchStream {
+			{ CheckUUID:"uuid", Name:"requests", Kind:FETCH_KIND_NUMERIC, Transform:FETCH_TRANSFORM_COUNTER },
+			{ CheckUUID:"uuid", Name:"version", Kind:FETCH_KIND_TEXT },
+		},
+	}
+
+	if _, err := client.FetchData(ctx, fr); err != nil {
+		t.Fatalf("FetchData failed unexpectedly: %s\n", err.Error())
+	}
+	req := <- requests
+	expect(t, req.Method, "POST")
+	expect(t, req.Path, "/v2/fetch")
+
+	var body map[string]interface{}
+	json.Unmarshal([]byte(req.Body), &body)
+	expect(t, body["start"], float64(1500000000))
+	expect(t, body["period"], float64(300))
+	expect(t, body["count"], float64(3))
+	streams := body["streams"].([]interface{})
+	expect(t, streams[0].(map[string]interface{})["transform"], "counter")
+	expect(t, streams[1].(map[string]interface{})["transform"], "none")
+	expect(t, body["reduce"].([]interface{})[0].(map[string]interface{})["method"], "pass")
+
+	// Invalid requests are not sent
+	fr.Period = time.Duration(1500) * time.Millisecond
+	fr.Streams[1].Transform = FETCH_TRANSFORM_DERIVE
+	_, err := client.FetchData(ctx, fr)
+	var verr ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Expected a ValidationError, got %v\n", err)
+	}
+	expect(t, len(verr.Problems), 2)
+}
+
+
+func TestFetchResult(t *testing.T) {
+	fr := FetchRequest {
+		Streams:	[]FetchStream {
+			{ Kind:FETCH_KIND_NUMERIC, Label:"requests" },
+			{ Kind:FETCH_KIND_TEXT, Label:"version" },
+			{ Kind:FETCH_KIND_HISTOGRAM, Label:"latency" },
+		},
+	}
+
+	var response fetchResponse
+	err := json.Unmarshal([]byte(`{
+		"head": { "start":1500000000, "period":60, "count":2 },
+		"data": [
+			[ 1.5, null ],
+			[ "1.2.3", "1.2.4" ],
+			[ { "+12e-001":3 }, [ "H[1.2e+01]=2" ] ]
+		]
+	}`), &response)
+	if err != nil {
+		t.Fatalf("Cannot decode response: %s\n", err.Error())
+	}
+
+	result, err := response.result(fr)
+	if err != nil {
+		t.Fatalf("Cannot convert response: %s\n", err.Error())
+	}
+
+	expect(t, result.Period, time.Minute)
+	expect(t, len(result.Series), 3)
+	expect(t, result.Series[0].Label, "requests")
+	expect(t, *result.Series[0].Points[0].Value, 1.5)
+	expect(t, result.Series[0].Points[1].Value == nil, true)
+	expect(t, result.Series[0].Points[1].Timestamp, time.Unix(1500000060, 0))
+	expect(t, *result.Series[1].Points[1].Text, "1.2.4")
+	expect(t, result.Series[2].Points[0].Histogram.String(), "H[1.2e+00]=3")
+	expect(t, result.Series[2].Points[1].Histogram.String(), "H[1.2e+01]=2")
+}
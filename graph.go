@@ -0,0 +1,297 @@
+package circonus
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Structures ============================================================ //
+
+// A Graph is a visualization of one or more metrics, composites and guides.
+//
+// Circonus adds fields to its graph representation over time.  Any field not
+// modelled here is kept in Extra when a Graph is decoded, and written back
+// out when it is encoded, so that updating a Graph does not discard them.
+type Graph struct {
+	CID               CID                        `json:"_cid,omitempty"`
+	AccessKeys        []GraphAccessKey           `json:"access_keys"`
+	Composites        []GraphComposite           `json:"composites"`
+	Datapoints        []GraphDatapoint           `json:"datapoints"`
+	Description       string                     `json:"description"`
+	Guides            []GraphGuide               `json:"guides"`
+	LineStyle         *string                    `json:"line_style"`
+	LogarithmicLeftY  *string                    `json:"logarithmic_left_y"`
+	LogarithmicRightY *string                    `json:"logarithmic_right_y"`
+	MaxLeftY          *GraphNumber               `json:"max_left_y"`
+	MaxRightY         *GraphNumber               `json:"max_right_y"`
+	MinLeftY          *GraphNumber               `json:"min_left_y"`
+	MinRightY         *GraphNumber               `json:"min_right_y"`
+	Notes             *string                    `json:"notes"`
+	Style             *string                    `json:"style"`
+	Tags              []string                   `json:"tags"`
+	Title             string                     `json:"title"`
+	Extra             map[string]json.RawMessage `json:"-"`
+}
+
+// A GraphAccessKey grants anonymous access to a graph, for embedding it
+// outside of Circonus.
+type GraphAccessKey struct {
+	Active         bool                       `json:"active"`
+	Height         int                        `json:"height"`
+	Key            string                     `json:"key"`
+	Legend         bool                       `json:"legend"`
+	LockDate       bool                       `json:"lock_date"`
+	LockMode       string                     `json:"lock_mode"`
+	LockRangeEnd   int64                      `json:"lock_range_end"`
+	LockRangeStart int64                      `json:"lock_range_start"`
+	LockShowTimes  bool                       `json:"lock_show_times"`
+	LockZoom       string                     `json:"lock_zoom"`
+	Nickname       string                     `json:"nickname"`
+	Title          bool                       `json:"title"`
+	Width          int                        `json:"width"`
+	XLabel         bool                       `json:"x_label"`
+	YLabel         bool                       `json:"y_label"`
+	Extra          map[string]json.RawMessage `json:"-"`
+}
+
+// A GraphComposite plots the result of a formula over a graph's datapoints.
+type GraphComposite struct {
+	Axis          string                     `json:"axis"` // Either "l" or "r"
+	Color         *string                    `json:"color"`
+	DataFormula   *string                    `json:"data_formula"`
+	Hidden        bool                       `json:"hidden"`
+	LegendFormula *string                    `json:"legend_formula"`
+	Name          string                     `json:"name"`
+	Stack         *int                       `json:"stack"`
+	Extra         map[string]json.RawMessage `json:"-"`
+}
+
+// A GraphDatapoint plots a single metric of a check.
+type GraphDatapoint struct {
+	Alpha         *GraphNumber               `json:"alpha,omitempty"`
+	Axis          string                     `json:"axis"` // Either "l" or "r"
+	CAQL          *string                    `json:"caql,omitempty"`
+	CheckID       int                        `json:"check_id,omitempty"`
+	Color         *string                    `json:"color"`
+	DataFormula   *string                    `json:"data_formula"`
+	Derive        GraphDerive                `json:"derive"`
+	Hidden        bool                       `json:"hidden"`
+	LegendFormula *string                    `json:"legend_formula"`
+	MetricName    string                     `json:"metric_name,omitempty"`
+	MetricType    string                     `json:"metric_type,omitempty"`
+	Name          string                     `json:"name"`
+	Stack         *int                       `json:"stack"`
+	Extra         map[string]json.RawMessage `json:"-"`
+}
+
+// A GraphGuide draws a horizontal line on a graph.
+type GraphGuide struct {
+	Color         string                     `json:"color"`
+	DataFormula   string                     `json:"data_formula"`
+	Hidden        bool                       `json:"hidden"`
+	LegendFormula *string                    `json:"legend_formula"`
+	Name          string                     `json:"name"`
+	Extra         map[string]json.RawMessage `json:"-"`
+}
+
+// A number within a graph, such as an axis limit.  Circonus returns these
+// as either JSON numbers or strings, and accepts strings, which is how they
+// are encoded.
+type GraphNumber float64
+
+// Describes how a datapoint's values are transformed before being plotted.
+// Circonus represents "no transformation" as false rather than a string,
+// which is what an empty GraphDerive is encoded as.
+type GraphDerive string
+
+// Datapoint transformations.
+const (
+	DERIVE_NONE    GraphDerive = ""
+	DERIVE_COUNTER GraphDerive = "counter"
+	DERIVE_DERIVE  GraphDerive = "derive"
+	DERIVE_GAUGE   GraphDerive = "gauge"
+)
+
+// Graph API ============================================================= //
+
+// Creates a new graph and returns it as stored by Circonus.
+func (c *Client) CreateGraph(ctx context.Context, g *Graph) (*Graph, error) {
+	return pointerTo(NewResource[Graph](c, GRAPH).Create(ctx, *g))
+}
+
+// Retrieves the graph with the given CID (eg. "/graph/<uuid>").
+func (c *Client) GetGraph(ctx context.Context, cid CID) (*Graph, error) {
+	return pointerTo(NewResource[Graph](c, GRAPH).Get(ctx, cid))
+}
+
+// Replaces an existing graph, identified by its CID, and returns the updated
+// graph.
+func (c *Client) UpdateGraph(ctx context.Context, g *Graph) (*Graph, error) {
+	if g.CID == "" {
+		return nil, RequestDataError{Reason: "graph has no CID"}
+	}
+	return pointerTo(NewResource[Graph](c, GRAPH).Update(ctx, g.CID, *g))
+}
+
+// Deletes the graph with the given CID.
+func (c *Client) DeleteGraph(ctx context.Context, cid CID) error {
+	return NewResource[Graph](c, GRAPH).Delete(ctx, cid)
+}
+
+// Retrieves every graph visible to the Client's access token,
+// optionally filtered and paged by the given options.
+func (c *Client) ListGraphs(ctx context.Context, opts *ListOptions) ([]Graph, error) {
+	return NewResource[Graph](c, GRAPH).List(ctx, opts)
+}
+
+// JSON Encoding ========================================================= //
+
+func (d GraphDerive) MarshalJSON() ([]byte, error) {
+	if d == DERIVE_NONE {
+		return []byte("false"), nil
+	}
+	return json.Marshal(string(d))
+}
+
+func (d *GraphDerive) UnmarshalJSON(data []byte) error {
+	if string(data) == "false" || string(data) == "null" {
+		*d = DERIVE_NONE
+		return nil
+	}
+	return json.Unmarshal(data, (*string)(d))
+}
+
+func (n GraphNumber) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatFloat(float64(n), 'f', -1, 64))
+}
+
+func (n *GraphNumber) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return json.Unmarshal(data, (*float64)(n))
+	}
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return err
+	}
+	*n = GraphNumber(value)
+	return nil
+}
+
+func (g Graph) MarshalJSON() ([]byte, error) {
+	type plain Graph
+	return marshalWithExtra(plain(g), g.Extra)
+}
+
+func (g *Graph) UnmarshalJSON(data []byte) error {
+	type plain Graph
+	if err := json.Unmarshal(data, (*plain)(g)); err != nil {
+		return err
+	}
+	extra, err := unmarshalExtra(data, g)
+	g.Extra = extra
+	return err
+}
+
+func (k GraphAccessKey) MarshalJSON() ([]byte, error) {
+	type plain GraphAccessKey
+	return marshalWithExtra(plain(k), k.Extra)
+}
+
+func (k *GraphAccessKey) UnmarshalJSON(data []byte) error {
+	type plain GraphAccessKey
+	if err := json.Unmarshal(data, (*plain)(k)); err != nil {
+		return err
+	}
+	extra, err := unmarshalExtra(data, k)
+	k.Extra = extra
+	return err
+}
+
+func (cp GraphComposite) MarshalJSON() ([]byte, error) {
+	type plain GraphComposite
+	return marshalWithExtra(plain(cp), cp.Extra)
+}
+
+func (cp *GraphComposite) UnmarshalJSON(data []byte) error {
+	type plain GraphComposite
+	if err := json.Unmarshal(data, (*plain)(cp)); err != nil {
+		return err
+	}
+	extra, err := unmarshalExtra(data, cp)
+	cp.Extra = extra
+	return err
+}
+
+func (dp GraphDatapoint) MarshalJSON() ([]byte, error) {
+	type plain GraphDatapoint
+	return marshalWithExtra(plain(dp), dp.Extra)
+}
+
+func (dp *GraphDatapoint) UnmarshalJSON(data []byte) error {
+	type plain GraphDatapoint
+	if err := json.Unmarshal(data, (*plain)(dp)); err != nil {
+		return err
+	}
+	extra, err := unmarshalExtra(data, dp)
+	dp.Extra = extra
+	return err
+}
+
+func (gd GraphGuide) MarshalJSON() ([]byte, error) {
+	type plain GraphGuide
+	return marshalWithExtra(plain(gd), gd.Extra)
+}
+
+func (gd *GraphGuide) UnmarshalJSON(data []byte) error {
+	type plain GraphGuide
+	if err := json.Unmarshal(data, (*plain)(gd)); err != nil {
+		return err
+	}
+	extra, err := unmarshalExtra(data, gd)
+	gd.Extra = extra
+	return err
+}
+
+// Encodes a struct as a JSON object, adding any extra fields that were not
+// otherwise present in its encoding.
+func marshalWithExtra(v interface{}, extra map[string]json.RawMessage) ([]byte, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return encoded, err
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return nil, err
+	}
+	for key, value := range extra {
+		if _, known := fields[key]; !known {
+			fields[key] = value
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// Returns the fields of a JSON object which do not correspond to any field
+// of the struct pointed to by v.  Returns nil if there are no such fields.
+func unmarshalExtra(data []byte, v interface{}) (map[string]json.RawMessage, error) {
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	t := reflect.TypeOf(v).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		delete(fields, name)
+	}
+
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return fields, nil
+}
@@ -0,0 +1,82 @@
+package circonus
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+
+func TestGraphNumbers(t *testing.T) {
+	var g Graph
+	err := json.Unmarshal([]byte(`{
+		"max_left_y": "100",
+		"min_left_y": 0,
+		"max_right_y": 2.5,
+		"min_right_y": null,
+		"datapoints": [ { "alpha":"0.3" }, { "alpha":1 } ]
+	}`), &g)
+	if err != nil {
+		t.Fatalf("Cannot decode graph: %s\n", err.Error())
+	}
+	expect(t, *g.MaxLeftY, GraphNumber(100))
+	expect(t, *g.MinLeftY, GraphNumber(0))
+	expect(t, *g.MaxRightY, GraphNumber(2.5))
+	expect(t, g.MinRightY == nil, true)
+	expect(t, *g.Datapoints[0].Alpha, GraphNumber(0.3))
+	expect(t, *g.Datapoints[1].Alpha, GraphNumber(1))
+
+	encoded, _ := json.Marshal(g)
+	var fields map[string]interface{}
+	json.Unmarshal(encoded, &fields)
+	expect(t, fields["max_right_y"], "2.5")
+	expect(t, fields["min_right_y"], nil)
+
+	if err := json.Unmarshal([]byte(`{ "max_left_y":"high" }`), &g); err == nil {
+		t.Errorf("Non-numeric axis limit was decoded\n")
+	}
+}
+
+
+func TestGraphExtra(t *testing.T) {
+	var g Graph
+	err := json.Unmarshal([]byte(`{
+		"_cid": "/graph/1",
+		"title": "requests",
+		"overlay_sets": { "a":{ "title":"last week" } },
+		"datapoints": [ { "name":"requests", "axis":"l", "derive":false, "search":"service:api" } ],
+		"guides": [ { "name":"limit", "data_formula":"100", "visible":true } ]
+	}`), &g)
+	if err != nil {
+		t.Fatalf("Cannot decode graph: %s\n", err.Error())
+	}
+	expect(t, string(g.Extra["overlay_sets"]), `{ "a":{ "title":"last week" } }`)
+	expect(t, string(g.Datapoints[0].Extra["search"]), `"service:api"`)
+	expect(t, len(g.Datapoints[0].Extra), 1)
+
+	// Unknown fields survive a round trip, at every level
+	encoded, err := json.Marshal(g)
+	if err != nil {
+		t.Fatalf("Cannot encode graph: %s\n", err.Error())
+	}
+	var fields map[string]interface{}
+	json.Unmarshal(encoded, &fields)
+	expect(t, fields["overlay_sets"].(map[string]interface{})["a"].(map[string]interface{})["title"], "last week")
+	expect(t, fields["datapoints"].([]interface{})[0].(map[string]interface{})["search"], "service:api")
+	expect(t, fields["guides"].([]interface{})[0].(map[string]interface{})["visible"], true)
+
+	// Known fields take precedence over extra fields of the same name
+	g.Title = "latency"
+	g.Extra["title"] = json.RawMessage(`"stale"`)
+	encoded, _ = json.Marshal(g)
+	fields = nil
+	json.Unmarshal(encoded, &fields)
+	expect(t, fields["title"], "latency")
+
+	var decoded Graph
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("Cannot decode graph: %s\n", err.Error())
+	}
+	expect(t, decoded.Title, "latency")
+	expect(t, decoded.Guides[0].Name, "limit")
+	expect(t, string(decoded.Guides[0].Extra["visible"]), "true")
+}
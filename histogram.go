@@ -0,0 +1,281 @@
+package circonus
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Structures ============================================================ //
+
+// A Histogram counts samples in log-linear bins, as used by Circonus to
+// store distributions (known as "circllhist"): each bin covers values
+// sharing their two most significant decimal digits, such as [12, 13) or
+// [0.0012, 0.0013), or (-13, -12] for negative values.  Zero has a bin of its
+// own.  Values are therefore recorded with an error of at most 10%, however
+// large or small they are.
+//
+// Histograms are encoded in JSON as a list of bins in the form accepted by
+// HTTPTrap checks (see Bins), and may be given directly as the value of a
+// TrapMetric of type TRAP_HISTOGRAM.
+//
+// Histograms are not safe for concurrent use.
+type Histogram struct {
+	bins map[histogramBin]uint64
+}
+
+// A bin of a Histogram, covering values from val * 10^(exp - 1).  Val is
+// zero for the bin holding zero, and otherwise has a magnitude from 10 to
+// 99, taking the sign of the values it holds.
+type histogramBin struct {
+	val int8
+	exp int8
+}
+
+// Histogram API ========================================================= //
+
+// Creates an empty Histogram.
+func NewHistogram() *Histogram {
+	return &Histogram{bins: make(map[histogramBin]uint64)}
+}
+
+// Records a sample.
+func (h *Histogram) Record(value float64) {
+	h.RecordN(value, 1)
+}
+
+// Records a sample occurring the given number of times.  Samples which are
+// not finite, or too large in magnitude for any bin, are ignored.
+func (h *Histogram) RecordN(value float64, count uint64) {
+	if bin, ok := binOf(value); ok && count > 0 {
+		h.bins[bin] += count
+	}
+}
+
+// Returns the number of samples recorded.
+func (h *Histogram) Count() uint64 {
+	var count uint64
+	for _, n := range h.bins {
+		count += n
+	}
+	return count
+}
+
+// Adds the samples recorded by another Histogram to this one.
+func (h *Histogram) Merge(other *Histogram) {
+	for bin, count := range other.bins {
+		h.bins[bin] += count
+	}
+}
+
+// Returns the approximate value below which the given fraction of samples
+// lie, assuming samples are spread evenly within each bin.  Quantile(0)
+// returns the lower bound of the lowest bin, and Quantile(1) the upper bound
+// of the highest.
+//
+// NaN is returned if the Histogram is empty or q is not between zero and
+// one.
+func (h *Histogram) Quantile(q float64) float64 {
+	count := h.Count()
+	if count == 0 || !(q >= 0 && q <= 1) {
+		return math.NaN()
+	}
+
+	target := q * float64(count)
+	seen := 0.0
+	keys := h.sorted()
+	for i, bin := range keys {
+		n := float64(h.bins[bin])
+		if seen+n >= target || i == len(keys)-1 {
+			lower, width := bin.lower(), bin.width()
+			fraction := (target - seen) / n
+			return lower + width*math.Min(fraction, 1)
+		}
+		seen += n
+	}
+	return math.NaN() // Unreachable, as the Histogram is not empty
+}
+
+// Returns the approximate value of each of the given quantiles.  See
+// Quantile.
+func (h *Histogram) Quantiles(qs ...float64) []float64 {
+	values := make([]float64, len(qs))
+	for i, q := range qs {
+		values[i] = h.Quantile(q)
+	}
+	return values
+}
+
+// Returns the approximate mean of the samples recorded, taking each to lie
+// at the midpoint of its bin, or NaN if the Histogram is empty.
+func (h *Histogram) Mean() float64 {
+	count := h.Count()
+	if count == 0 {
+		return math.NaN()
+	}
+
+	sum := 0.0
+	for bin, n := range h.bins {
+		sum += (bin.lower() + bin.width()/2) * float64(n)
+	}
+	return sum / float64(count)
+}
+
+// Returns the Histogram's bins in the form accepted by HTTPTrap checks
+// (eg. "H[1.2e+01]=3"), in ascending order of value.
+func (h *Histogram) Bins() []string {
+	keys := h.sorted()
+	bins := make([]string, len(keys))
+	for i, bin := range keys {
+		bins[i] = fmt.Sprintf("H[%.1e]=%d", bin.value(), h.bins[bin])
+	}
+	return bins
+}
+
+// Returns the Histogram's bins as a single string, separated by commas.
+func (h *Histogram) String() string {
+	return strings.Join(h.Bins(), ",")
+}
+
+func (h *Histogram) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.Bins())
+}
+
+func (h *Histogram) UnmarshalJSON(data []byte) error {
+	var bins []string
+	if err := json.Unmarshal(data, &bins); err != nil {
+		return err
+	}
+
+	parsed, err := ParseHistogram(bins...)
+	if err != nil {
+		return err
+	}
+	*h = *parsed
+	return nil
+}
+
+// Parses bins in the form returned by Bins (eg. "H[1.2e+01]=3").  Bins of
+// the same value are summed.
+func ParseHistogram(bins ...string) (*Histogram, error) {
+	h := NewHistogram()
+	for _, bin := range bins {
+		value, count, err := parseBin(bin)
+		if err != nil {
+			return nil, err
+		}
+
+		b, ok := binOf(value)
+		if !ok {
+			return nil, ValidationError{Resource: "histogram", Problems: []string{"bin \"" + bin + "\" is out of range"}}
+		}
+		h.bins[b] += count
+	}
+	return h, nil
+}
+
+// Histogram Bins ======================================================== //
+
+// Returns the bin holding a value, or false if no bin can hold it.  Values
+// too small in magnitude for any bin are counted as zero.
+func binOf(value float64) (histogramBin, bool) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return histogramBin{}, false
+	}
+	if value == 0 {
+		return histogramBin{}, true
+	}
+
+	// Two significant digits, corrected for rounding in the logarithm near
+	// powers of ten
+	magnitude := math.Abs(value)
+	exp := int(math.Floor(math.Log10(magnitude)))
+	if exp < math.MinInt8-1 {
+		return histogramBin{}, true
+	}
+	if exp > math.MaxInt8+1 {
+		return histogramBin{}, false
+	}
+	digits := magnitude / math.Pow(10, float64(exp-1))
+	if digits < 10 {
+		digits, exp = digits*10, exp-1
+	} else if digits >= 100 {
+		digits, exp = digits/10, exp+1
+	}
+	val := int(math.Floor(digits * (1 + 1e-12))) // Tolerate division error
+	if val > 99 {
+		val = 99
+	}
+
+	if exp < math.MinInt8 {
+		return histogramBin{}, true
+	}
+	if exp > math.MaxInt8 {
+		return histogramBin{}, false
+	}
+
+	if value < 0 {
+		val = -val
+	}
+	return histogramBin{val: int8(val), exp: int8(exp)}, true
+}
+
+// Returns the lowest magnitude value held by a bin.
+func (b histogramBin) value() float64 {
+	return float64(b.val) * math.Pow(10, float64(b.exp)-1)
+}
+
+// Returns the lowest value held by a bin.
+func (b histogramBin) lower() float64 {
+	if b.val < 0 {
+		return b.value() - b.width()
+	}
+	return b.value()
+}
+
+// Returns the size of the range of values held by a bin.
+func (b histogramBin) width() float64 {
+	if b.val == 0 {
+		return 0
+	}
+	return math.Pow(10, float64(b.exp)-1)
+}
+
+// Returns a Histogram's bins in ascending order of value.
+func (h *Histogram) sorted() []histogramBin {
+	keys := make([]histogramBin, 0, len(h.bins))
+	for bin := range h.bins {
+		keys = append(keys, bin)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].value() < keys[j].value()
+	})
+	return keys
+}
+
+// Parses a single bin in the form "H[<value>]=<count>".
+func parseBin(bin string) (float64, uint64, error) {
+	malformed := ValidationError{Resource: "histogram", Problems: []string{"malformed bin \"" + bin + "\""}}
+
+	rest, ok := strings.CutPrefix(bin, "H[")
+	if !ok {
+		return 0, 0, malformed
+	}
+	value, count, ok := strings.Cut(rest, "]=")
+	if !ok {
+		return 0, 0, malformed
+	}
+
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, 0, malformed
+	}
+	n, err := strconv.ParseUint(count, 10, 64)
+	if err != nil {
+		return 0, 0, malformed
+	}
+	return v, n, nil
+}
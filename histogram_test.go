@@ -0,0 +1,129 @@
+package circonus
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"testing"
+	"testing/quick"
+)
+
+
+/*
+ * Creates a Histogram from integers, scaled to cover a wide range of
+ * magnitudes.  Used with testing/quick, whose random floats are mostly too
+ * large for any bin.
+ */
+func histogramOf(samples []int32) (*Histogram, []float64) {
+	h := NewHistogram()
+	values := make([]float64, len(samples))
+	for i, sample := range samples {
+		values[i] = float64(sample) / 1000
+		h.Record(values[i])
+	}
+	return h, values
+}
+
+
+func TestHistogramBins(t *testing.T) {
+	h := NewHistogram()
+	h.Record(12)
+	h.Record(12.9)
+	h.Record(0)
+	h.Record(-12)
+	h.Record(0.0012)
+	h.Record(1)
+	h.Record(math.NaN())
+
+	expect(t, h.Count(), uint64(6))
+	expect(t, h.String(), "H[-1.2e+01]=1,H[0.0e+00]=1,H[1.2e-03]=1,H[1.0e+00]=1,H[1.2e+01]=2")
+
+	encoded, _ := json.Marshal(h)
+	expect(t, string(encoded), `["H[-1.2e+01]=1","H[0.0e+00]=1","H[1.2e-03]=1","H[1.0e+00]=1","H[1.2e+01]=2"]`)
+
+	for _, bin := range []string{ "", "H[1.2e+01]", "H[x]=1", "H[1.2e+01]=-1", "1.2e+01=1" } {
+		if _, err := ParseHistogram(bin); err == nil {
+			t.Errorf("Malformed bin %q was parsed\n", bin)
+		}
+	}
+}
+
+
+func TestHistogramQuantiles(t *testing.T) {
+	h := NewHistogram()
+	for i := 10; i < 20; i++ {
+		h.Record(float64(i))
+	}
+
+	quantiles := h.Quantiles(0, 0.25, 0.5, 1)
+	expect(t, quantiles[0], float64(10))
+	expect(t, quantiles[1], 12.5)
+	expect(t, quantiles[2], float64(15))
+	expect(t, quantiles[3], float64(20))
+	expect(t, h.Mean(), 15.0)
+
+	single := NewHistogram()
+	single.RecordN(-12, 4)
+	expect(t, single.Quantile(0), float64(-13))
+	expect(t, single.Quantile(0.5), -12.5)
+
+	if !math.IsNaN(NewHistogram().Quantile(0.5)) || !math.IsNaN(h.Quantile(1.5)) {
+		t.Errorf("Expected NaN for an empty histogram or invalid quantile\n")
+	}
+}
+
+
+func TestHistogramProperties(t *testing.T) {
+	// Every finite sample is counted
+	count := func(samples []int32) bool {
+		h, _ := histogramOf(samples)
+		return h.Count() == uint64(len(samples))
+	}
+
+	// Merging is equivalent to recording every sample in one histogram
+	merge := func(a []int32, b []int32) bool {
+		ha, _ := histogramOf(a)
+		hb, _ := histogramOf(b)
+		all, _ := histogramOf(append(append([]int32{}, a...), b...))
+		ha.Merge(hb)
+		return ha.String() == all.String()
+	}
+
+	// Bins survive serialization
+	roundTrip := func(samples []int32) bool {
+		h, _ := histogramOf(samples)
+		parsed, err := ParseHistogram(h.Bins()...)
+		return err == nil && parsed.String() == h.String()
+	}
+
+	// Quantiles are ordered, and lie within 10% of the sample at their rank
+	quantiles := func(samples []int32, q uint8) bool {
+		if len(samples) == 0 {
+			return true
+		}
+		h, values := histogramOf(samples)
+		sort.Float64s(values)
+
+		fraction := float64(q) / math.MaxUint8
+		rank := int(math.Ceil(fraction * float64(len(values)))) - 1
+		if rank < 0 {
+			rank = 0
+		}
+		exact, approx := values[rank], h.Quantile(fraction)
+		if math.Abs(approx - exact) > math.Abs(exact) * 0.1 + 1e-9 {
+			return false
+		}
+		return h.Quantile(0) <= approx && approx <= h.Quantile(1)
+	}
+
+	for name, property := range map[string]interface{} {
+		"count":				count,
+		"merge":				merge,
+		"round trip":		roundTrip,
+		"quantiles":		quantiles,
+	} {
+		if err := quick.Check(property, nil); err != nil {
+			t.Errorf("Property %q does not hold: %s\n", name, err.Error())
+		}
+	}
+}
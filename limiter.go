@@ -0,0 +1,184 @@
+package circonus
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Structures ============================================================ //
+
+// A RateLimiter limits the rate at which requests are sent to Circonus
+// using a token bucket: requests may be sent in bursts, up to the bucket's
+// size, after which they are sent no faster than the bucket is refilled.
+//
+// Circonus rate limits by access token, so RateLimiters are shared by every
+// Client configured with the same token.  RateLimiters are safe for
+// concurrent use.
+type RateLimiter struct {
+	mutex  sync.Mutex
+	burst  float64   // Size of the bucket
+	last   time.Time // Time tokens were last added to the bucket
+	rate   float64   // Tokens added per second
+	stats  RateLimiterStats
+	tokens float64 // Tokens available; negative when requests are waiting
+}
+
+// RateLimiterStats describe how requests have been delayed by a RateLimiter.
+type RateLimiterStats struct {
+	Requests  int64         // Requests admitted
+	Delayed   int64         // Requests which had to wait to be admitted
+	TotalWait time.Duration // Time spent waiting by all requests
+	MaxWait   time.Duration // Longest time spent waiting by any request
+}
+
+// Constants & Data ====================================================== //
+
+// RateLimiters in use, by access token.
+var limiters = struct {
+	sync.Mutex
+	byToken map[string]*RateLimiter
+}{
+	byToken: make(map[string]*RateLimiter),
+}
+
+// Client API ============================================================ //
+
+// Limits the Client to sending the given number of requests per second,
+// with bursts of up to the given size.  Returns the RateLimiter used, which
+// is shared with every other Client using the same access token.
+//
+// Since the limit applies to the access token, it replaces any limit
+// previously set by another Client sharing it, silently changing the rate
+// at which that Client sends requests too, and resets the RateLimiter's
+// statistics.  A rate of zero or less removes the limit from this Client
+// only; ReleaseRateLimit also forgets the shared RateLimiter.
+//
+// Like the Client's exported fields, the limit should not be changed while
+// requests are in flight.
+func (c *Client) SetRateLimit(rate float64, burst int) *RateLimiter {
+	if rate <= 0 {
+		c.limiter = nil
+		return nil
+	}
+
+	limiters.Lock()
+	defer limiters.Unlock()
+
+	limiter, exists := limiters.byToken[c.token]
+	if !exists {
+		limiter = &RateLimiter{}
+		limiters.byToken[c.token] = limiter
+	}
+	limiter.SetLimit(rate, burst)
+
+	c.limiter = limiter
+	return limiter
+}
+
+// Removes the limit from the Client, and forgets the RateLimiter shared by
+// Clients using its access token, so that the next call to SetRateLimit for
+// that token creates a new one.  Other Clients already sharing the old
+// RateLimiter continue to use it until their own limits are set.
+func (c *Client) ReleaseRateLimit() {
+	limiters.Lock()
+	defer limiters.Unlock()
+
+	if c.limiter != nil && limiters.byToken[c.token] == c.limiter {
+		delete(limiters.byToken, c.token)
+	}
+	c.limiter = nil
+}
+
+// Returns the RateLimiter used by the Client, or nil if it is not limited.
+func (c *Client) RateLimiter() *RateLimiter {
+	return c.limiter
+}
+
+// Rate Limiter ========================================================== //
+
+// Changes the rate and burst size of a RateLimiter, and resets its
+// statistics.  Bursts are at least a single request.  A rate of zero or less
+// removes the limit, so that requests are admitted straight away, and a
+// limit set afterwards starts with a full bucket.
+func (l *RateLimiter) SetLimit(rate float64, burst int) {
+	if burst < 1 {
+		burst = 1
+	}
+	if rate < 0 {
+		rate = 0
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	// The bucket is only refilled while a limit is set
+	if l.rate <= 0 {
+		l.tokens = float64(burst)
+		l.last = time.Now()
+	} else {
+		l.refill(time.Now())
+	}
+	l.rate = rate
+	l.burst = float64(burst)
+	l.stats = RateLimiterStats{}
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
+
+// Blocks until a request may be sent, or the context is cancelled.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	l.mutex.Lock()
+	if l.rate <= 0 {
+		l.stats.Requests += 1
+		l.mutex.Unlock()
+		return nil
+	}
+	now := time.Now()
+	l.refill(now)
+	l.tokens -= 1
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mutex.Unlock()
+
+	if delay > 0 {
+		if err := wait(ctx, delay); err != nil {
+			l.mutex.Lock()
+			l.tokens += 1 // Return the unused token
+			l.mutex.Unlock()
+			return err
+		}
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.stats.Requests += 1
+	if delay > 0 {
+		l.stats.Delayed += 1
+		l.stats.TotalWait += delay
+		if delay > l.stats.MaxWait {
+			l.stats.MaxWait = delay
+		}
+	}
+	return nil
+}
+
+// Returns statistics on the requests admitted by a RateLimiter.
+func (l *RateLimiter) Stats() RateLimiterStats {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.stats
+}
+
+// Adds tokens accumulated since the bucket was last refilled.  Must be
+// called with the mutex held.
+func (l *RateLimiter) refill(now time.Time) {
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+}
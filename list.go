@@ -0,0 +1,158 @@
+package circonus
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+)
+
+// Structures ============================================================ //
+
+// ListOptions select which items of a resource are returned by List.
+type ListOptions struct {
+	// Maximum number of items to return.  Zero returns every item in a
+	// single response, except when iterating, where a default page size is
+	// used instead.
+	Size int
+
+	// Number of items to skip before the first one returned.
+	From int
+
+	// Search expression and filters items must match.  If nil, every item
+	// is returned.
+	Query *Query
+}
+
+// An Iterator steps through the items of a resource one at a time,
+// fetching them from Circonus a page at a time as it goes.
+//
+//	it := client.ListIter(ctx, "/check_bundle", nil)
+//	for it.Next() {
+//		item := it.Item()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type Iterator[T any] struct {
+	client *Client
+	ctx    context.Context
+	done   bool // No pages remain to be fetched
+	err    error
+	index  int // Index of the next item within the page
+	item   T
+	last   []T // Most recently fetched page, to detect servers not paging
+	opts   ListOptions
+	page   []T
+	path   string
+}
+
+// A ListIterator steps through items decoded as they would be by List.
+type ListIterator = Iterator[interface{}]
+
+// Constants & Data ====================================================== //
+
+const default_page_size int = 100
+
+// List Options ========================================================== //
+
+// Returns the querystring parameters representing a set of options.
+func (o *ListOptions) parameters() url.Values {
+	if o == nil {
+		return make(url.Values)
+	}
+
+	params := o.Query.Values()
+	if o.Size > 0 {
+		params.Set("size", strconv.Itoa(o.Size))
+	}
+	if o.From > 0 {
+		params.Set("from", strconv.Itoa(o.From))
+	}
+	return params
+}
+
+// List Iterator ========================================================= //
+
+// Returns an iterator over the items of a resource selected by the given
+// options.  Items are fetched a page at a time, of the size given by the
+// options or a default size if none is given, starting from the given
+// offset.
+func (c *Client) ListIter(ctx context.Context, resource string, opts *ListOptions) *ListIterator {
+	return newIterator[interface{}](ctx, c, collectionPath(resource), opts)
+}
+
+func newIterator[T any](ctx context.Context, c *Client, path string, opts *ListOptions) *Iterator[T] {
+	it := &Iterator[T]{
+		client: c,
+		ctx:    ctx,
+		path:   path,
+	}
+	if opts != nil {
+		it.opts = *opts
+	}
+	if it.opts.Size <= 0 {
+		it.opts.Size = default_page_size
+	}
+	return it
+}
+
+// Advances the iterator to the next item, fetching the next page of items
+// if needed.  Returns false when no items remain or an error occurs.
+func (it *Iterator[T]) Next() bool {
+	for it.index >= len(it.page) {
+		if it.done || it.err != nil {
+			var zero T
+			it.item = zero
+			return false
+		}
+		it.fetch()
+	}
+
+	it.item = it.page[it.index]
+	it.index += 1
+	return true
+}
+
+// Returns the item the iterator is positioned at.
+func (it *Iterator[T]) Item() T {
+	return it.item
+}
+
+// Returns the error, if any, which ended iteration.
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// Fetches the next page of items.
+func (it *Iterator[T]) fetch() {
+	var page []T
+	req := request{
+		Method:     http.MethodGet,
+		Resource:   it.path,
+		Parameters: it.opts.parameters(),
+		Result:     &page,
+	}
+	if _, err := it.client.send(it.ctx, req); err != nil {
+		it.err = err
+		return
+	}
+
+	// A server ignoring the page requested may return the same items again
+	// and again, so iteration also ends once a page makes no progress
+	if len(page) == 0 || reflect.DeepEqual(page, it.last) {
+		it.page = nil
+		it.done = true
+		return
+	}
+
+	it.page = page
+	it.last = page
+	it.index = 0
+	it.opts.From += len(page)
+	if len(page) < it.opts.Size {
+		it.done = true
+	}
+}
@@ -0,0 +1,174 @@
+package circonus
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// Structures ============================================================ //
+
+// A Maintenance window silences alerts from an item for a period of time.
+//
+// Item identifies what is silenced: the CID of a check or rule set, the
+// account's CID, or the name of a host, as given by Type.  Start and Stop
+// are given in seconds since the epoch.
+type Maintenance struct {
+	CID        CID                   `json:"_cid,omitempty"`
+	Item       string                `json:"item"`
+	Notes      string                `json:"notes"`
+	Severities MaintenanceSeverities `json:"severities"`
+	Start      int64                 `json:"start"`
+	Stop       int64                 `json:"stop"`
+	Tags       []string              `json:"tags"`
+	Type       string                `json:"type"` // One of the MAINTENANCE_* constants
+}
+
+// The severities of alerts silenced by a Maintenance window, from one to
+// five.  Circonus represents severities as strings.
+type MaintenanceSeverities []int
+
+// Constants & Data ====================================================== //
+
+// Types of item which may be placed in maintenance.
+const (
+	MAINTENANCE_ACCOUNT  string = "account"
+	MAINTENANCE_CHECK    string = "check"
+	MAINTENANCE_HOST     string = "host"
+	MAINTENANCE_RULE_SET string = "rule_set"
+)
+
+// Resources whose CIDs identify the items of each type.  Hosts are given by
+// name rather than CID.
+var maintenanceItems = map[string]resource{
+	MAINTENANCE_ACCOUNT:  ACCOUNT,
+	MAINTENANCE_CHECK:    CHECK,
+	MAINTENANCE_HOST:     "",
+	MAINTENANCE_RULE_SET: RULE_SET,
+}
+
+// Maintenance API ======================================================= //
+
+// Creates a new maintenance window and returns it as stored by Circonus.
+func (c *Client) CreateMaintenance(ctx context.Context, m *Maintenance) (*Maintenance, error) {
+	return pointerTo(NewResource[Maintenance](c, MAINTENANCE).Create(ctx, *m))
+}
+
+// Retrieves the maintenance window with the given CID (eg.
+// "/maintenance/1234").
+func (c *Client) GetMaintenance(ctx context.Context, cid CID) (*Maintenance, error) {
+	return pointerTo(NewResource[Maintenance](c, MAINTENANCE).Get(ctx, cid))
+}
+
+// Replaces an existing maintenance window, identified by its CID, and
+// returns the updated window.
+func (c *Client) UpdateMaintenance(ctx context.Context, m *Maintenance) (*Maintenance, error) {
+	if m.CID == "" {
+		return nil, RequestDataError{Reason: "maintenance window has no CID"}
+	}
+	return pointerTo(NewResource[Maintenance](c, MAINTENANCE).Update(ctx, m.CID, *m))
+}
+
+// Deletes the maintenance window with the given CID, ending it early if it
+// is in progress.
+func (c *Client) DeleteMaintenance(ctx context.Context, cid CID) error {
+	return NewResource[Maintenance](c, MAINTENANCE).Delete(ctx, cid)
+}
+
+// Retrieves every maintenance window visible to the Client's access token,
+// optionally filtered and paged by the given options.
+func (c *Client) ListMaintenance(ctx context.Context, opts *ListOptions) ([]Maintenance, error) {
+	return NewResource[Maintenance](c, MAINTENANCE).List(ctx, opts)
+}
+
+// Silences alerts of the given severities from a check, beginning now and
+// lasting for the given duration.  Alerts of every severity are silenced if
+// none are given.
+func (c *Client) SilenceCheckFor(ctx context.Context, checkCID CID, duration time.Duration, severities ...int) (*Maintenance, error) {
+	cid, err := ParseCID(CHECK, string(checkCID))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(severities) == 0 {
+		for severity := min_severity; severity <= max_severity; severity++ {
+			severities = append(severities, severity)
+		}
+	}
+
+	start := time.Now()
+	return c.CreateMaintenance(ctx, &Maintenance{
+		Item:       string(cid),
+		Severities: severities,
+		Start:      start.Unix(),
+		Stop:       start.Add(duration).Unix(),
+		Tags:       []string{},
+		Type:       MAINTENANCE_CHECK,
+	})
+}
+
+// Checks that a maintenance window identifies its item correctly, silences
+// valid severities, and stops after it starts.
+func (m Maintenance) Validate() error {
+	var problems []string
+
+	kind, known := maintenanceItems[m.Type]
+	switch {
+	case !known:
+		problems = append(problems, "unknown item type \""+m.Type+"\"")
+	case m.Item == "":
+		problems = append(problems, "no item specified")
+	case kind != "":
+		if _, err := ParseCID(kind, m.Item); err != nil {
+			problems = append(problems, "item is not a "+string(kind)+" CID")
+		}
+	}
+
+	if len(m.Severities) == 0 {
+		problems = append(problems, "no severities specified")
+	}
+	for _, severity := range m.Severities {
+		if severity < min_severity || severity > max_severity {
+			problems = append(problems, "severity "+strconv.Itoa(severity)+" is not between 1 and 5")
+		}
+	}
+
+	if m.Stop <= m.Start {
+		problems = append(problems, "stop must be after start")
+	}
+
+	if len(problems) > 0 {
+		return ValidationError{Resource: string(MAINTENANCE), Problems: problems}
+	}
+	return nil
+}
+
+// JSON Encoding ========================================================= //
+
+func (s MaintenanceSeverities) MarshalJSON() ([]byte, error) {
+	severities := make([]string, len(s))
+	for i, severity := range s {
+		severities[i] = strconv.Itoa(severity)
+	}
+	return json.Marshal(severities)
+}
+
+// Severities are accepted as either strings or numbers.
+func (s *MaintenanceSeverities) UnmarshalJSON(data []byte) error {
+	var values []json.Number
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+
+	severities := make(MaintenanceSeverities, len(values))
+	for i, value := range values {
+		severity, err := strconv.Atoi(value.String())
+		if err != nil {
+			return err
+		}
+		severities[i] = severity
+	}
+	*s = severities
+	return nil
+}
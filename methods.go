@@ -0,0 +1,95 @@
+package circonus
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Structures ============================================================ //
+
+// Internal type listing the HTTP methods a resource supports, both on its
+// collection (eg. "/check_bundle") and on its items (eg. "/check_bundle/1").
+type methodSupport struct {
+	collection []string
+	item       []string
+}
+
+// Constants & Data ====================================================== //
+
+var (
+	// Resources which may only be read.
+	readOnly = methodSupport{
+		collection: []string{http.MethodGet},
+		item:       []string{http.MethodGet},
+	}
+
+	// Resources which exist independently of the API, but may be modified.
+	editable = methodSupport{
+		collection: []string{http.MethodGet},
+		item:       []string{http.MethodGet, http.MethodPut},
+	}
+
+	// Resources which may be created and updated, but not deleted.
+	undeletable = methodSupport{
+		collection: []string{http.MethodGet, http.MethodPost},
+		item:       []string{http.MethodGet, http.MethodPut},
+	}
+
+	// Endpoints which accept queries in the querystring of GET requests.
+	getQuery = methodSupport{
+		collection: []string{http.MethodGet},
+	}
+
+	// Endpoints which accept queries in the body of POST requests.
+	postQuery = methodSupport{
+		collection: []string{http.MethodPost},
+	}
+
+	// Resources which may be created, read, updated and deleted.
+	full = methodSupport{
+		collection: []string{http.MethodGet, http.MethodPost},
+		item:       []string{http.MethodGet, http.MethodPut, http.MethodDelete},
+	}
+)
+
+// Methods supported by each resource of the Circonus v2 API.  Requests to
+// resources not listed here are not checked.
+var supportedMethods = map[resource]methodSupport{
+	ACCOUNT:         editable,
+	ACKNOWLEDGEMENT: undeletable,
+	ALERT:           readOnly,
+	ANNOTATION:      full,
+	BROKER:          readOnly,
+	CAQL:            getQuery,
+	CHECK:           readOnly,
+	CHECK_BUNDLE:    full,
+	CONTACT_GROUP:   full,
+	FETCH:           postQuery,
+	GRAPH:           full,
+	MAINTENANCE:     full,
+	RULE_SET:        full,
+	RULE_SET_GROUP:  full,
+	TEMPLATE:        full,
+	USER:            editable,
+}
+
+// Method Verification =================================================== //
+
+// Returns an UnsupportedMethodError if a request uses a method its resource
+// does not support.
+func verifyMethod(r request) error {
+	parts := strings.SplitN(strings.TrimPrefix(r.Resource, "/"), "/", 2)
+	support, known := supportedMethods[resource(parts[0])]
+	if !known {
+		return nil
+	}
+
+	allowed := support.collection
+	if len(parts) > 1 {
+		allowed = support.item
+	}
+	if !contains(allowed, r.Method) {
+		return UnsupportedMethodError{Method: r.Method, Resource: r.Resource}
+	}
+	return nil
+}
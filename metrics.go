@@ -0,0 +1,232 @@
+package circonus
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+)
+
+// Structures ============================================================ //
+
+// A Metrics registry aggregates metrics in memory and periodically submits
+// them to an HTTPTrap check bundle:
+//
+//	metrics, err := NewMetrics(ctx, MetricsConfig{SubmissionURL: url})
+//	defer metrics.Close(ctx)
+//
+//	metrics.Increment("requests")
+//	defer metrics.Time("request_duration")()
+//
+// Each submission reports the metrics recorded since the previous one:
+// counters are summed, gauges and text metrics report their latest value,
+// and timings and histogram samples are reported as log-linear histograms.
+//
+// Metrics registries are safe for concurrent use.
+type Metrics struct {
+	mutex      sync.Mutex
+	counters   map[string]uint64
+	gauges     map[string]float64
+	histograms map[string]*Histogram
+	text       map[string]string
+
+	bundle *CheckBundle // Found or created by the registry, if any
+	trap   *Trap        // Submits the registry's metrics periodically
+}
+
+// MetricsConfig configures a Metrics registry created by NewMetrics.
+type MetricsConfig struct {
+	// SubmissionURL is the URL of the HTTPTrap check bundle metrics are
+	// submitted to.
+	//
+	// If empty, the check bundle described by CheckBundle is found or
+	// created with Client (see EnsureCheckBundle).
+	SubmissionURL string
+
+	// Client used to find or create a check bundle when no SubmissionURL is
+	// given.
+	Client *Client
+
+	// CheckBundle describes the check bundle to find or create when no
+	// SubmissionURL is given.  If nil, an HTTPTrap check bundle for the
+	// current host is used.
+	CheckBundle *CheckBundleSpec
+
+	// Interval between submissions.  The default value is 10 seconds.
+	Interval time.Duration
+
+	// OnError receives the errors of failed periodic submissions, and a
+	// DuplicateCheckBundlesError if several check bundles match CheckBundle
+	// (unless it sets its own OnDuplicates).  These are otherwise discarded.
+	OnError func(error)
+
+	// TrapOptions configure the Trap metrics are submitted with, taking
+	// precedence over Interval and OnError.
+	TrapOptions []TrapOption
+}
+
+// Metrics API =========================================================== //
+
+// Creates a Metrics registry, finding or creating its check bundle if
+// required, and starts submitting its metrics periodically.
+func NewMetrics(ctx context.Context, config MetricsConfig) (*Metrics, error) {
+	m := &Metrics{
+		counters:   make(map[string]uint64),
+		gauges:     make(map[string]float64),
+		histograms: make(map[string]*Histogram),
+		text:       make(map[string]string),
+	}
+	interval := config.Interval
+	if interval <= 0 {
+		interval = default_trap_interval
+	}
+
+	submissionURL := config.SubmissionURL
+	if submissionURL == "" {
+		if config.Client == nil {
+			return nil, OptionError{Option: "MetricsConfig", Reason: "no submission URL or client given"}
+		}
+
+		spec := defaultMetricsBundle()
+		if config.CheckBundle != nil {
+			spec = *config.CheckBundle
+		}
+
+		// Duplicate bundles are reported, but do not prevent submission
+		if spec.OnDuplicates == nil && config.OnError != nil {
+			spec.OnDuplicates = func(kept CID, duplicates []CID) {
+				config.OnError(DuplicateCheckBundlesError{Kept: kept, Duplicates: duplicates})
+			}
+		}
+		bundle, url, err := config.Client.EnsureCheckBundle(ctx, spec)
+		if err != nil {
+			return nil, err
+		}
+		if url == "" {
+			return nil, MalformedResponseError{Reason: "check bundle has no submission URL"}
+		}
+		m.bundle, submissionURL = bundle, url
+	}
+
+	// The Trap submits periodically, collecting the registry's metrics first
+	options := []TrapOption{WithTrapInterval(interval), WithTrapErrorHandler(config.OnError)}
+	options = append(options, config.TrapOptions...)
+	options = append(options, withTrapPreFlush(m.drain))
+	trap, err := NewTrap(submissionURL, options...)
+	if err != nil {
+		return nil, err
+	}
+	m.trap = trap
+	return m, nil
+}
+
+// Returns the check bundle found or created for the registry, or nil if it
+// was given a submission URL.
+func (m *Metrics) CheckBundle() *CheckBundle {
+	return m.bundle
+}
+
+// Adds one to the value of a counter.
+func (m *Metrics) Increment(name string) {
+	m.Add(name, 1)
+}
+
+// Adds to the value of a counter.
+func (m *Metrics) Add(name string, delta uint64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.counters[name] += delta
+}
+
+// Sets the value of a gauge.
+func (m *Metrics) Gauge(name string, value float64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.gauges[name] = value
+}
+
+// Sets the value of a text metric.
+func (m *Metrics) Text(name string, value string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.text[name] = value
+}
+
+// Records a sample in a histogram.
+func (m *Metrics) Sample(name string, value float64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	h, exists := m.histograms[name]
+	if !exists {
+		h = NewHistogram()
+		m.histograms[name] = h
+	}
+	h.Record(value)
+}
+
+// Records a duration, in seconds, in a histogram.
+func (m *Metrics) Timing(name string, d time.Duration) {
+	m.Sample(name, d.Seconds())
+}
+
+// Starts timing an operation, returning a function which records its
+// duration when called.
+func (m *Metrics) Time(name string) func() {
+	start := time.Now()
+	return func() {
+		m.Timing(name, time.Since(start))
+	}
+}
+
+// Submits every metric recorded since the last submission.
+func (m *Metrics) Flush(ctx context.Context) error {
+	return m.trap.Flush(ctx)
+}
+
+// Stops periodic submission and submits any remaining metrics, as for
+// Trap.Close.
+func (m *Metrics) Close(ctx context.Context) error {
+	return m.trap.Close(ctx)
+}
+
+// Metrics Internals ===================================================== //
+
+// Moves the metrics recorded since the last submission to the registry's
+// Trap, to be submitted.
+func (m *Metrics) drain(trap *Trap) {
+	m.mutex.Lock()
+	counters, gauges, histograms, text := m.counters, m.gauges, m.histograms, m.text
+	m.counters = make(map[string]uint64)
+	m.gauges = make(map[string]float64)
+	m.histograms = make(map[string]*Histogram)
+	m.text = make(map[string]string)
+	m.mutex.Unlock()
+
+	for name, value := range counters {
+		trap.Counter(name, value)
+	}
+	for name, value := range gauges {
+		trap.Gauge(name, value)
+	}
+	for name, h := range histograms {
+		trap.HistogramBins(name, h.Bins()...)
+	}
+	for name, value := range text {
+		trap.Text(name, value)
+	}
+}
+
+// Describes the HTTPTrap check bundle used for the current host when no
+// other is given.
+func defaultMetricsBundle() CheckBundleSpec {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "localhost"
+	}
+	return CheckBundleSpec{
+		DisplayName: host + " metrics",
+		Target:      host,
+		Type:        CHECK_TYPE_HTTPTRAP,
+	}
+}
@@ -0,0 +1,106 @@
+package circonus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+
+func TestMetrics(t *testing.T) {
+	trap := createTrapServer(0)
+	defer trap.Close()
+	server := createBundleServer(trap.URL)
+	defer server.Close()
+	client := createClient(server.Server)
+	ctx := context.Background()
+
+	metrics, err := NewMetrics(ctx, MetricsConfig { Client:client, Interval:time.Hour })
+	if err != nil {
+		t.Fatalf("Cannot create metrics: %s\n", err.Error())
+	}
+
+	expect(t, server.created, 1)
+	expect(t, metrics.CheckBundle().CID, CID("/check_bundle/1000"))
+	expect(t, metrics.CheckBundle().Type, CHECK_TYPE_HTTPTRAP)
+
+	metrics.Increment("requests")
+	metrics.Add("requests", 2)
+	metrics.Gauge("queue", 3)
+	metrics.Text("version", "1.2.3")
+	metrics.Sample("size", 12)
+	metrics.Sample("size", 12.5)
+	metrics.Sample("size", 0.0012)
+	metrics.Timing("duration", time.Duration(15) * time.Millisecond)
+
+	if err := metrics.Close(ctx); err != nil {
+		t.Fatalf("Close failed unexpectedly: %s\n", err.Error())
+	}
+
+	submission := <- trap.submissions
+	expect(t, submission["requests"].Value, float64(3))
+	expect(t, submission["queue"].Value, float64(3))
+	expect(t, submission["version"].Value, "1.2.3")
+	expect(t, submission["size"].Type, TRAP_HISTOGRAM)
+	expect(t, len(submission["size"].Value.([]interface{})), 2)
+	expect(t, submission["size"].Value.([]interface{})[0], "H[1.2e-03]=1")
+	expect(t, submission["size"].Value.([]interface{})[1], "H[1.2e+01]=2")
+	expect(t, submission["duration"].Value.([]interface{})[0], "H[1.5e-02]=1")
+
+	// Duplicate bundles are reported as errors, but do not prevent submission
+	duplicate := *metrics.CheckBundle()
+	duplicate.CID = "/check_bundle/999"
+	server.store(duplicate)
+	var reported []error
+	metrics, err = NewMetrics(ctx, MetricsConfig {
+		Client:		client,
+		Interval:	time.Hour,
+		OnError:	func(err error) { reported = append(reported, err) },
+	})
+	if err != nil {
+		t.Fatalf("Cannot create metrics: %s\n", err.Error())
+	}
+	expect(t, metrics.CheckBundle().CID, CID("/check_bundle/999"))
+	expect(t, len(reported), 1)
+	var derr DuplicateCheckBundlesError
+	if !errors.As(reported[0], &derr) || !errors.Is(reported[0], ErrDuplicateBundles) {
+		t.Fatalf("Expected a DuplicateCheckBundlesError, got %v\n", reported[0])
+	}
+	expect(t, derr.Duplicates[0], CID("/check_bundle/1000"))
+	metrics.Close(ctx)
+
+	if _, err := NewMetrics(ctx, MetricsConfig {}); err == nil {
+		t.Errorf("Metrics created without a submission URL or client\n")
+	}
+}
+
+
+func TestMetricsInterval(t *testing.T) {
+	trap := createTrapServer(0)
+	defer trap.Close()
+
+	metrics, err := NewMetrics(context.Background(), MetricsConfig {
+		SubmissionURL:	trap.URL,
+		Interval:				time.Duration(20) * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Cannot create metrics: %s\n", err.Error())
+	}
+
+	// The registry's metrics are submitted periodically by its trap
+	metrics.Gauge("queue", 1)
+	select {
+	case submission := <- trap.submissions:
+		expect(t, submission["queue"].Value, float64(1))
+	case <- time.After(time.Second):
+		t.Errorf("Metrics were not submitted periodically\n")
+	}
+
+	// Closing the registry closes its trap, submitting remaining metrics
+	metrics.Gauge("queue", 2)
+	if err := metrics.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed unexpectedly: %s\n", err.Error())
+	}
+	expect(t, (<- trap.submissions)["queue"].Value, float64(2))
+}
@@ -0,0 +1,100 @@
+package circonus
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Structures ============================================================ //
+
+// An Option configures a Client as it is created by NewClient.
+type Option func(*Client) error
+
+// Options =============================================================== //
+
+// Sets the base URL of the Circonus API, for use with Circonus Inside
+// deployments (eg. "https://circonus.example.com").  The URL must use the
+// http or https scheme, and may include a path prefix.
+func WithHost(host string) Option {
+	return func(c *Client) error {
+		u, err := url.Parse(host)
+		if err != nil {
+			return OptionError{Option: "WithHost", Reason: err.Error()}
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return OptionError{Option: "WithHost", Reason: "scheme must be http or https"}
+		}
+		if u.Host == "" {
+			return OptionError{Option: "WithHost", Reason: "no host name given"}
+		}
+		if u.RawQuery != "" || u.Fragment != "" {
+			return OptionError{Option: "WithHost", Reason: "URL cannot have a query or fragment"}
+		}
+
+		c.host = strings.TrimSuffix(u.String(), "/")
+		return nil
+	}
+}
+
+// Sets the version of the Circonus API requests are made against (eg. "v2").
+// An empty version omits the version from request paths entirely.
+func WithAPIVersion(version string) Option {
+	return func(c *Client) error {
+		version = strings.Trim(version, "/")
+		if version == "" {
+			c.path = ""
+		} else {
+			c.path = "/" + version
+		}
+		return nil
+	}
+}
+
+// Sets the HTTP client used to make requests.  The Client's Timeout is not
+// applied to requests made with it, so it should set its own.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) error {
+		if client == nil {
+			return OptionError{Option: "WithHTTPClient", Reason: "client is nil"}
+		}
+		c.httpclient = client
+		return nil
+	}
+}
+
+// Sets the transport used to make requests.  Ignored if WithHTTPClient is
+// also given.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) error {
+		if transport == nil {
+			return OptionError{Option: "WithTransport", Reason: "transport is nil"}
+		}
+		c.transport = transport
+		return nil
+	}
+}
+
+// Sets the User-Agent header sent with each request.
+func WithUserAgent(agent string) Option {
+	return func(c *Client) error {
+		c.useragent = agent
+		return nil
+	}
+}
+
+// Sets the policy deciding whether and when failed requests are retried.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) error {
+		c.RetryPolicy = policy
+		return nil
+	}
+}
+
+// Limits the rate at which requests are sent.  See Client.SetRateLimit.
+func WithRateLimit(rate float64, burst int) Option {
+	return func(c *Client) error {
+		c.SetRateLimit(rate, burst)
+		return nil
+	}
+}
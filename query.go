@@ -0,0 +1,96 @@
+package circonus
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Structures ============================================================ //
+
+// A Query selects items of a resource by search expression and field
+// filters.  Queries are built up by chaining calls, for example:
+//
+//	q := NewQuery().Tag("env:prod").Where("type", "httptrap").Search("web")
+//
+// Giving several values for the same filter matches items with any of them.
+type Query struct {
+	filters url.Values
+	tags    []string
+	terms   []string
+}
+
+// Query Builder ========================================================= //
+
+// Creates an empty Query, which matches every item.
+func NewQuery() *Query {
+	return &Query{
+		filters: make(url.Values),
+	}
+}
+
+// Matches items containing the given text.
+func (q *Query) Search(text string) *Query {
+	q.terms = append(q.terms, text)
+	return q
+}
+
+// Matches items with all of the given tags (eg. "env:prod").
+func (q *Query) Tag(tags ...string) *Query {
+	q.tags = append(q.tags, tags...)
+	return q
+}
+
+// Matches items whose field equals any of the given values.
+func (q *Query) Where(field string, values ...string) *Query {
+	return q.filter("f_"+field, values)
+}
+
+// Matches items whose field matches any of the given wildcard patterns,
+// where "*" matches any sequence of characters.
+func (q *Query) Wildcard(field string, patterns ...string) *Query {
+	return q.filter("f_"+field+"_wildcard", patterns)
+}
+
+// Matches items whose list-valued field contains any of the given values.
+func (q *Query) Has(field string, values ...string) *Query {
+	return q.filter("f_"+field+"_has", values)
+}
+
+// Returns the querystring parameters representing the Query.
+func (q *Query) Values() url.Values {
+	values := make(url.Values)
+	if q == nil {
+		return values
+	}
+
+	for key, list := range q.filters {
+		values[key] = append([]string{}, list...)
+	}
+	if search := q.expression(); search != "" {
+		values.Set("search", search)
+	}
+	return values
+}
+
+// Returns the Query encoded as a querystring.
+func (q *Query) String() string {
+	return q.Values().Encode()
+}
+
+func (q *Query) filter(key string, values []string) *Query {
+	if q.filters == nil {
+		q.filters = make(url.Values)
+	}
+	q.filters[key] = append(q.filters[key], values...)
+	return q
+}
+
+// Returns the Circonus search expression for the Query's search terms and
+// tags, eg. "(tags:env:prod,role:web)apache".
+func (q *Query) expression() string {
+	var expression string
+	if len(q.tags) > 0 {
+		expression = "(tags:" + strings.Join(q.tags, ",") + ")"
+	}
+	return expression + strings.Join(q.terms, " ")
+}
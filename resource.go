@@ -0,0 +1,125 @@
+package circonus
+
+import (
+	"context"
+	"net/http"
+)
+
+// Structures ============================================================ //
+
+// A Resource provides typed access to one of Circonus' endpoints, decoding
+// responses directly into values of type T.  T is typically one of the
+// structures modelling Circonus' resources, such as CheckBundle:
+//
+//	bundles := NewResource[CheckBundle](client, CHECK_BUNDLE)
+//	bundle, err := bundles.Get(ctx, "/check_bundle/1234")
+//
+// Resources hold no state of their own and are cheap to create.
+type Resource[T any] struct {
+	client *Client
+	kind   resource
+}
+
+// Resource API ========================================================== //
+
+// Creates a Resource for the given endpoint.
+func NewResource[T any](c *Client, r resource) *Resource[T] {
+	return &Resource[T]{
+		client: c,
+		kind:   r,
+	}
+}
+
+// Creates a new item and returns it as stored by Circonus.
+func (r *Resource[T]) Create(ctx context.Context, item T) (T, error) {
+	var result T
+	req := request{
+		Method:   http.MethodPost,
+		Resource: r.kind.path(),
+		Data:     item,
+		Result:   &result,
+	}
+	_, err := r.client.send(ctx, req)
+	return result, err
+}
+
+// Retrieves the item with the given CID.
+func (r *Resource[T]) Get(ctx context.Context, cid CID) (T, error) {
+	var result T
+	path, err := r.kind.item(cid)
+	if err != nil {
+		return result, err
+	}
+
+	req := request{
+		Method:   http.MethodGet,
+		Resource: path,
+		Result:   &result,
+	}
+	_, err = r.client.send(ctx, req)
+	return result, err
+}
+
+// Replaces the item with the given CID and returns the updated item.
+func (r *Resource[T]) Update(ctx context.Context, cid CID, item T) (T, error) {
+	var result T
+	path, err := r.kind.item(cid)
+	if err != nil {
+		return result, err
+	}
+
+	req := request{
+		Method:   http.MethodPut,
+		Resource: path,
+		Data:     item,
+		Result:   &result,
+	}
+	_, err = r.client.send(ctx, req)
+	return result, err
+}
+
+// Deletes the item with the given CID.
+func (r *Resource[T]) Delete(ctx context.Context, cid CID) error {
+	path, err := r.kind.item(cid)
+	if err != nil {
+		return err
+	}
+
+	req := request{
+		Method:   http.MethodDelete,
+		Resource: path,
+	}
+	_, err = r.client.send(ctx, req)
+	return err
+}
+
+// Retrieves the items selected by the given options, or every item if no
+// options are given.
+func (r *Resource[T]) List(ctx context.Context, opts *ListOptions) ([]T, error) {
+	var result []T
+	req := request{
+		Method:     http.MethodGet,
+		Resource:   r.kind.path(),
+		Parameters: opts.parameters(),
+		Result:     &result,
+	}
+	if _, err := r.client.send(ctx, req); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Returns an iterator over the items selected by the given options.  See
+// Client.ListIter.
+func (r *Resource[T]) Iter(ctx context.Context, opts *ListOptions) *Iterator[T] {
+	return newIterator[T](ctx, r.client, r.kind.path(), opts)
+}
+
+// Returns a pointer to a value, unless accompanied by an error.  Used to
+// adapt the results of a Resource to functions returning pointers.
+func pointerTo[T any](value T, err error) (*T, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
@@ -0,0 +1,209 @@
+package circonus
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Structures ============================================================ //
+
+// A RetryPolicy decides whether, and after how long, a failed request is
+// retried.
+//
+// Policies are consulted after every failed attempt other than those that
+// were cancelled.  When a policy declines to retry a rate limited request,
+// the Client returns a RateLimitExceededError.
+type RetryPolicy interface {
+	Backoff(a RetryAttempt) (delay time.Duration, retry bool)
+}
+
+// A RetryAttempt describes a failed request being considered for retry.
+type RetryAttempt struct {
+	Attempt    int           // Attempts made so far, starting at one
+	Elapsed    time.Duration // Time since the first attempt began
+	Method     string        // HTTP method of the request
+	StatusCode int           // Zero if no response was received
+	RetryAfter time.Duration // Delay requested by Circonus, if any
+	Err        error         // Error the attempt failed with
+}
+
+// ExponentialBackoff is a RetryPolicy which waits exponentially longer
+// between each attempt, randomized to avoid many clients retrying in step.
+//
+// Rate limited requests are always eligible for retry, since Circonus will
+// not have acted upon them.  Other failures are only retried when
+// RetryServerErrors is set, and then only for idempotent requests failing
+// with a 5xx response or a transient network error.
+//
+// Where Circonus specifies how long to wait before retrying, that delay is
+// used instead of the computed one.
+//
+// Zero valued fields take on their default values.
+type ExponentialBackoff struct {
+	// Total number of attempts made, including the first.  Defaults to five.
+	MaxAttempts int
+
+	// Delay before the first retry.  Defaults to one second.
+	InitialInterval time.Duration
+
+	// Upper limit on the delay before any retry.  Defaults to 30 seconds.
+	MaxInterval time.Duration
+
+	// Factor by which the delay grows after each retry.  Defaults to two.
+	Multiplier float64
+
+	// Fraction of each delay which is randomized, such that a delay d is
+	// chosen from [d - Jitter*d, d + Jitter*d].  Zero disables jitter.
+	Jitter float64
+
+	// Time after the first attempt beyond which no retry will be scheduled.
+	// Defaults to two minutes.
+	MaxElapsedTime time.Duration
+
+	// Whether idempotent requests are retried after server errors and
+	// transient network failures.
+	RetryServerErrors bool
+}
+
+// Constants & Data ====================================================== //
+
+const (
+	default_retry_interval     time.Duration = time.Duration(1) * time.Second
+	default_retry_max_elapsed  time.Duration = time.Duration(2) * time.Minute
+	default_retry_max_interval time.Duration = time.Duration(30) * time.Second
+	default_retry_multiplier   float64       = 2
+)
+
+var idempotentMethods = map[string]bool{
+	http.MethodDelete:  true,
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodPut:     true,
+}
+
+// Exponential Backoff =================================================== //
+
+func (b ExponentialBackoff) Backoff(a RetryAttempt) (time.Duration, bool) {
+	if a.Attempt >= b.maxAttempts() || !b.retryable(a) {
+		return 0, false
+	}
+
+	delay := a.RetryAfter
+	if delay <= 0 {
+		delay = b.interval(a.Attempt)
+	}
+
+	maxElapsed := b.MaxElapsedTime
+	if maxElapsed <= 0 {
+		maxElapsed = default_retry_max_elapsed
+	}
+	if a.Elapsed+delay > maxElapsed {
+		return 0, false
+	}
+
+	return delay, true
+}
+
+func (b ExponentialBackoff) maxAttempts() int {
+	if b.MaxAttempts <= 0 {
+		return default_retry_attempts
+	}
+	return b.MaxAttempts
+}
+
+// Returns the randomized delay before retrying after the given attempt.
+func (b ExponentialBackoff) interval(attempt int) time.Duration {
+	initial, max, multiplier := b.InitialInterval, b.MaxInterval, b.Multiplier
+	if initial <= 0 {
+		initial = default_retry_interval
+	}
+	if max <= 0 {
+		max = default_retry_max_interval
+	}
+	if multiplier < 1 {
+		multiplier = default_retry_multiplier
+	}
+
+	delay := float64(initial)
+	for i := 1; i < attempt && delay < float64(max); i++ {
+		delay *= multiplier
+	}
+	if delay > float64(max) {
+		delay = float64(max)
+	}
+
+	if b.Jitter > 0 {
+		delta := b.Jitter * delay
+		delay = delay - delta + rand.Float64()*2*delta
+	}
+
+	return time.Duration(delay)
+}
+
+// Reports whether the failure of an attempt is one which may be retried.
+func (b ExponentialBackoff) retryable(a RetryAttempt) bool {
+	if _, ok := a.Err.(RateLimitError); ok {
+		return true
+	}
+	if !b.RetryServerErrors || !idempotentMethods[a.Method] {
+		return false
+	}
+	if a.StatusCode >= 500 {
+		return true
+	}
+	return a.StatusCode == 0 && isTransient(a.Err)
+}
+
+// Reports whether an error is a network failure likely to succeed if the
+// request is repeated, as opposed to a problem with the request itself.
+func isTransient(err error) bool {
+	var opErr *net.OpError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &opErr):
+		return true
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return true
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return true
+	}
+	return false
+}
+
+// Returns the delay Circonus has asked for before a rate limited request is
+// retried, or zero if it has not specified one.
+//
+// The Retry-After header may give either a number of seconds or a date.
+// Failing that, X-RateLimit-Reset gives the time at which the limit resets
+// in seconds since the epoch.
+func retryAfter(h http.Header) time.Duration {
+	if value := h.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+		if date, err := http.ParseTime(value); err == nil {
+			return positive(time.Until(date))
+		}
+	}
+
+	if value := h.Get("X-RateLimit-Reset"); value != "" {
+		if epoch, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return positive(time.Until(time.Unix(epoch, 0)))
+		}
+	}
+
+	return 0
+}
+
+func positive(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
@@ -0,0 +1,373 @@
+package circonus
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// Structures ============================================================ //
+
+// A RuleSet defines the conditions under which a metric raises alerts, and
+// who is notified of them at each severity.
+//
+// Rule sets may be sent through Client.Add or CreateRuleSet directly, but
+// are checked with Validate before being sent either way.  RuleSetBuilder
+// offers a more convenient way of constructing them.
+type RuleSet struct {
+	CID           CID              `json:"_cid,omitempty"`
+	CheckCID      CID              `json:"check"`
+	ContactGroups map[string][]CID `json:"contact_groups"` // Keyed by severity, "1" through "5"
+	Derive        *string          `json:"derive"`
+	Link          *string          `json:"link"`
+	MetricName    string           `json:"metric_name"`
+	MetricType    string           `json:"metric_type"`
+	Notes         *string          `json:"notes"`
+	Parent        *CID             `json:"parent,omitempty"`
+	Rules         []Rule           `json:"rules"`
+	Tags          []string         `json:"tags"`
+}
+
+// A Rule is a single alerting condition within a RuleSet.  Rules are
+// evaluated in order and the first to match determines the alert raised.
+type Rule struct {
+	Criteria          RuleCriteria `json:"criteria"`
+	Severity          int          `json:"severity"`
+	Value             string       `json:"value"`
+	Wait              int          `json:"wait"`                         // Minutes to wait before alerting
+	WindowingDuration int          `json:"windowing_duration,omitempty"` // Seconds
+	WindowingFunction *string      `json:"windowing_function"`
+}
+
+// Describes the condition a Rule tests a metric's value against.
+type RuleCriteria string
+
+// A RuleSetBuilder constructs a RuleSet incrementally.  Problems encountered
+// while building are collected and reported together by Build.
+type RuleSetBuilder struct {
+	problems []string
+	rs       RuleSet
+}
+
+// Constants & Data ====================================================== //
+
+// Types of metric a RuleSet may apply to.
+const (
+	METRIC_NUMERIC string = "numeric"
+	METRIC_TEXT    string = "text"
+)
+
+// Rule criteria.
+const (
+	CRITERIA_MAX_VALUE    RuleCriteria = "max value"
+	CRITERIA_MIN_VALUE    RuleCriteria = "min value"
+	CRITERIA_MATCH        RuleCriteria = "match"
+	CRITERIA_NOT_MATCH    RuleCriteria = "does not match"
+	CRITERIA_CONTAINS     RuleCriteria = "contains"
+	CRITERIA_NOT_CONTAINS RuleCriteria = "does not contain"
+	CRITERIA_ON_ABSENCE   RuleCriteria = "on absence"
+	CRITERIA_ON_CHANGE    RuleCriteria = "on change"
+)
+
+// Windowing functions, applied to numeric metrics over a rule's windowing
+// duration before they are compared against its value.
+const (
+	WINDOW_AVERAGE        string = "average"
+	WINDOW_STDDEV         string = "stddev"
+	WINDOW_DERIVE         string = "derive"
+	WINDOW_DERIVE_STDDEV  string = "derive_stddev"
+	WINDOW_COUNTER        string = "counter"
+	WINDOW_COUNTER_STDDEV string = "counter_stddev"
+)
+
+const (
+	min_severity int = 1
+	max_severity int = 5
+)
+
+// Metric types each criteria may be applied to.
+var criteriaMetricTypes = map[RuleCriteria][]string{
+	CRITERIA_MAX_VALUE:    {METRIC_NUMERIC},
+	CRITERIA_MIN_VALUE:    {METRIC_NUMERIC},
+	CRITERIA_MATCH:        {METRIC_TEXT},
+	CRITERIA_NOT_MATCH:    {METRIC_TEXT},
+	CRITERIA_CONTAINS:     {METRIC_TEXT},
+	CRITERIA_NOT_CONTAINS: {METRIC_TEXT},
+	CRITERIA_ON_ABSENCE:   {METRIC_NUMERIC, METRIC_TEXT},
+	CRITERIA_ON_CHANGE:    {METRIC_NUMERIC, METRIC_TEXT},
+}
+
+var windowingFunctions = map[string]bool{
+	WINDOW_AVERAGE:        true,
+	WINDOW_STDDEV:         true,
+	WINDOW_DERIVE:         true,
+	WINDOW_DERIVE_STDDEV:  true,
+	WINDOW_COUNTER:        true,
+	WINDOW_COUNTER_STDDEV: true,
+}
+
+// Validation ============================================================ //
+
+// Checks a RuleSet for problems Circonus would otherwise reject it for,
+// returning a ValidationError describing all of them.
+func (rs RuleSet) Validate() error {
+	var problems []string
+
+	if rs.CheckCID == "" {
+		problems = append(problems, "no check specified")
+	}
+	if rs.MetricName == "" {
+		problems = append(problems, "no metric name specified")
+	}
+	if rs.MetricType != METRIC_NUMERIC && rs.MetricType != METRIC_TEXT {
+		problems = append(problems, "unknown metric type \""+rs.MetricType+"\"")
+	}
+	if len(rs.Rules) == 0 {
+		problems = append(problems, "no rules specified")
+	}
+
+	for i, rule := range rs.Rules {
+		for _, problem := range rule.validate(rs.MetricType) {
+			problems = append(problems, "rule "+strconv.Itoa(i+1)+": "+problem)
+		}
+	}
+
+	for severity := range rs.ContactGroups {
+		if n, err := strconv.Atoi(severity); err != nil || n < min_severity || n > max_severity {
+			problems = append(problems, "contact groups given for invalid severity \""+severity+"\"")
+		}
+	}
+
+	if len(problems) > 0 {
+		return ValidationError{Resource: string(RULE_SET), Problems: problems}
+	}
+	return nil
+}
+
+// Returns the problems with a single rule, applied to a metric of the given
+// type.
+func (r Rule) validate(metricType string) []string {
+	var problems []string
+
+	if r.Severity < min_severity || r.Severity > max_severity {
+		problems = append(problems, "severity must be between 1 and 5")
+	}
+	if r.Wait < 0 {
+		problems = append(problems, "wait cannot be negative")
+	}
+
+	types, known := criteriaMetricTypes[r.Criteria]
+	if !known {
+		return append(problems, "unknown criteria \""+string(r.Criteria)+"\"")
+	}
+	if !contains(types, metricType) {
+		problems = append(problems, "criteria \""+string(r.Criteria)+"\" cannot be applied to "+metricType+" metrics")
+	}
+
+	switch r.Criteria {
+	case CRITERIA_MAX_VALUE, CRITERIA_MIN_VALUE:
+		if _, err := strconv.ParseFloat(r.Value, 64); err != nil {
+			problems = append(problems, "criteria \""+string(r.Criteria)+"\" requires a numeric value")
+		}
+	case CRITERIA_MATCH, CRITERIA_NOT_MATCH, CRITERIA_CONTAINS, CRITERIA_NOT_CONTAINS:
+		if r.Value == "" {
+			problems = append(problems, "criteria \""+string(r.Criteria)+"\" requires a value to compare against")
+		}
+	case CRITERIA_ON_ABSENCE:
+		if n, err := strconv.Atoi(r.Value); err != nil || n <= 0 {
+			problems = append(problems, "criteria \"on absence\" requires a positive number of seconds")
+		}
+	case CRITERIA_ON_CHANGE:
+		if r.Value != "" {
+			problems = append(problems, "criteria \"on change\" does not take a value")
+		}
+	}
+
+	if r.WindowingFunction != nil {
+		if !windowingFunctions[*r.WindowingFunction] {
+			problems = append(problems, "unknown windowing function \""+*r.WindowingFunction+"\"")
+		}
+		if metricType != METRIC_NUMERIC {
+			problems = append(problems, "windowing can only be applied to numeric metrics")
+		}
+		if r.WindowingDuration <= 0 {
+			problems = append(problems, "windowing requires a positive duration")
+		}
+	}
+
+	return problems
+}
+
+// Reports whether a list of strings contains a given value.
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Rule Set Builder ====================================================== //
+
+// Creates a builder for a RuleSet applying to the named metric of a check.
+func NewRuleSetBuilder(checkCID CID, metricName string, metricType string) *RuleSetBuilder {
+	return &RuleSetBuilder{
+		rs: RuleSet{
+			CheckCID:      checkCID,
+			ContactGroups: make(map[string][]CID),
+			MetricName:    metricName,
+			MetricType:    metricType,
+			Rules:         []Rule{},
+			Tags:          []string{},
+		},
+	}
+}
+
+// Adds a rule raising an alert when the metric exceeds a value.
+func (b *RuleSetBuilder) MaxValue(severity int, value float64) *RuleSetBuilder {
+	return b.Rule(CRITERIA_MAX_VALUE, severity, strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+// Adds a rule raising an alert when the metric falls below a value.
+func (b *RuleSetBuilder) MinValue(severity int, value float64) *RuleSetBuilder {
+	return b.Rule(CRITERIA_MIN_VALUE, severity, strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+// Adds a rule raising an alert when a text metric matches a value exactly.
+func (b *RuleSetBuilder) Match(severity int, value string) *RuleSetBuilder {
+	return b.Rule(CRITERIA_MATCH, severity, value)
+}
+
+// Adds a rule raising an alert when a text metric contains a value.
+func (b *RuleSetBuilder) Contains(severity int, value string) *RuleSetBuilder {
+	return b.Rule(CRITERIA_CONTAINS, severity, value)
+}
+
+// Adds a rule raising an alert when no data has been received for a metric
+// over the given period.
+func (b *RuleSetBuilder) OnAbsence(severity int, period time.Duration) *RuleSetBuilder {
+	return b.Rule(CRITERIA_ON_ABSENCE, severity, strconv.Itoa(int(period/time.Second)))
+}
+
+// Adds a rule raising an alert whenever the metric's value changes.
+func (b *RuleSetBuilder) OnChange(severity int) *RuleSetBuilder {
+	return b.Rule(CRITERIA_ON_CHANGE, severity, "")
+}
+
+// Adds a rule with arbitrary criteria.  The value is given in the form
+// Circonus expects it.
+func (b *RuleSetBuilder) Rule(criteria RuleCriteria, severity int, value string) *RuleSetBuilder {
+	b.rs.Rules = append(b.rs.Rules, Rule{
+		Criteria: criteria,
+		Severity: severity,
+		Value:    value,
+	})
+	return b
+}
+
+// Delays alerting on the most recently added rule until its condition has
+// held for the given duration, which must be a whole number of minutes.
+func (b *RuleSetBuilder) Wait(d time.Duration) *RuleSetBuilder {
+	if rule := b.lastRule("wait"); rule != nil {
+		if d%time.Minute != 0 {
+			b.problems = append(b.problems, "wait must be a whole number of minutes")
+		}
+		rule.Wait = int(d / time.Minute)
+	}
+	return b
+}
+
+// Applies a windowing function over the given duration to the most recently
+// added rule.
+func (b *RuleSetBuilder) Window(function string, d time.Duration) *RuleSetBuilder {
+	if rule := b.lastRule("window"); rule != nil {
+		rule.WindowingFunction = &function
+		rule.WindowingDuration = int(d / time.Second)
+	}
+	return b
+}
+
+// Notifies the given contact groups of alerts raised at a severity.
+func (b *RuleSetBuilder) Notify(severity int, contactGroupCIDs ...CID) *RuleSetBuilder {
+	key := strconv.Itoa(severity)
+	b.rs.ContactGroups[key] = append(b.rs.ContactGroups[key], contactGroupCIDs...)
+	return b
+}
+
+// Sets a link to documentation (eg. a runbook) included in notifications.
+func (b *RuleSetBuilder) Link(url string) *RuleSetBuilder {
+	b.rs.Link = &url
+	return b
+}
+
+// Sets notes included in notifications.
+func (b *RuleSetBuilder) Notes(notes string) *RuleSetBuilder {
+	b.rs.Notes = &notes
+	return b
+}
+
+// Adds tags to the RuleSet.
+func (b *RuleSetBuilder) Tags(tags ...string) *RuleSetBuilder {
+	b.rs.Tags = append(b.rs.Tags, tags...)
+	return b
+}
+
+// Returns the constructed RuleSet, or a ValidationError describing every
+// problem found with it.
+func (b *RuleSetBuilder) Build() (*RuleSet, error) {
+	rs := b.rs
+	err := rs.Validate()
+	if len(b.problems) > 0 {
+		problems := append([]string{}, b.problems...)
+		if verr, ok := err.(ValidationError); ok {
+			problems = append(problems, verr.Problems...)
+		}
+		err = ValidationError{Resource: string(RULE_SET), Problems: problems}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rs, nil
+}
+
+// Returns the most recently added rule, recording a problem if there is none.
+func (b *RuleSetBuilder) lastRule(operation string) *Rule {
+	if len(b.rs.Rules) == 0 {
+		b.problems = append(b.problems, operation+" given before any rule")
+		return nil
+	}
+	return &b.rs.Rules[len(b.rs.Rules)-1]
+}
+
+// Rule Set API ========================================================== //
+
+// Creates a new rule set and returns it as stored by Circonus.
+func (c *Client) CreateRuleSet(ctx context.Context, rs *RuleSet) (*RuleSet, error) {
+	return pointerTo(NewResource[RuleSet](c, RULE_SET).Create(ctx, *rs))
+}
+
+// Retrieves the rule set with the given CID.
+func (c *Client) GetRuleSet(ctx context.Context, cid CID) (*RuleSet, error) {
+	return pointerTo(NewResource[RuleSet](c, RULE_SET).Get(ctx, cid))
+}
+
+// Replaces an existing rule set, identified by its CID, and returns the
+// updated rule set.
+func (c *Client) UpdateRuleSet(ctx context.Context, rs *RuleSet) (*RuleSet, error) {
+	if rs.CID == "" {
+		return nil, RequestDataError{Reason: "rule set has no CID"}
+	}
+	return pointerTo(NewResource[RuleSet](c, RULE_SET).Update(ctx, rs.CID, *rs))
+}
+
+// Deletes the rule set with the given CID.
+func (c *Client) DeleteRuleSet(ctx context.Context, cid CID) error {
+	return NewResource[RuleSet](c, RULE_SET).Delete(ctx, cid)
+}
+
+// Retrieves every rule set visible to the Client's access token,
+// optionally filtered and paged by the given options.
+func (c *Client) ListRuleSets(ctx context.Context, opts *ListOptions) ([]RuleSet, error) {
+	return NewResource[RuleSet](c, RULE_SET).List(ctx, opts)
+}
@@ -0,0 +1,161 @@
+package circonus
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+
+func TestRuleSetValidate(t *testing.T) {
+	average := WINDOW_AVERAGE
+	unknown := "median"
+
+	tests := []struct {
+		name		string
+		metric	string
+		rule		Rule
+		problem	string
+	}{
+		{ "valid max value", METRIC_NUMERIC,
+			Rule{ Criteria:CRITERIA_MAX_VALUE, Severity:1, Value:"90" }, "" },
+		{ "valid windowing", METRIC_NUMERIC,
+			Rule{ Criteria:CRITERIA_MIN_VALUE, Severity:5, Value:"1.5", WindowingFunction:&average, WindowingDuration:300 }, "" },
+		{ "max value on text", METRIC_TEXT,
+			Rule{ Criteria:CRITERIA_MAX_VALUE, Severity:1, Value:"90" },
+			"rule 1: criteria \"max value\" cannot be applied to text metrics" },
+		{ "match on numeric", METRIC_NUMERIC,
+			Rule{ Criteria:CRITERIA_MATCH, Severity:1, Value:"down" },
+			"rule 1: criteria \"match\" cannot be applied to numeric metrics" },
+		{ "non-numeric max value", METRIC_NUMERIC,
+			Rule{ Criteria:CRITERIA_MAX_VALUE, Severity:1, Value:"high" },
+			"rule 1: criteria \"max value\" requires a numeric value" },
+		{ "non-numeric min value", METRIC_NUMERIC,
+			Rule{ Criteria:CRITERIA_MIN_VALUE, Severity:1, Value:"" },
+			"rule 1: criteria \"min value\" requires a numeric value" },
+		{ "empty match", METRIC_TEXT,
+			Rule{ Criteria:CRITERIA_CONTAINS, Severity:1 },
+			"rule 1: criteria \"contains\" requires a value to compare against" },
+		{ "zero absence", METRIC_NUMERIC,
+			Rule{ Criteria:CRITERIA_ON_ABSENCE, Severity:1, Value:"0" },
+			"rule 1: criteria \"on absence\" requires a positive number of seconds" },
+		{ "negative absence", METRIC_TEXT,
+			Rule{ Criteria:CRITERIA_ON_ABSENCE, Severity:1, Value:"-60" },
+			"rule 1: criteria \"on absence\" requires a positive number of seconds" },
+		{ "valued change", METRIC_NUMERIC,
+			Rule{ Criteria:CRITERIA_ON_CHANGE, Severity:1, Value:"1" },
+			"rule 1: criteria \"on change\" does not take a value" },
+		{ "windowed text", METRIC_TEXT,
+			Rule{ Criteria:CRITERIA_ON_CHANGE, Severity:1, WindowingFunction:&average, WindowingDuration:300 },
+			"rule 1: windowing can only be applied to numeric metrics" },
+		{ "unknown windowing", METRIC_NUMERIC,
+			Rule{ Criteria:CRITERIA_MAX_VALUE, Severity:1, Value:"1", WindowingFunction:&unknown, WindowingDuration:300 },
+			"rule 1: unknown windowing function \"median\"" },
+		{ "unwindowed duration", METRIC_NUMERIC,
+			Rule{ Criteria:CRITERIA_MAX_VALUE, Severity:1, Value:"1", WindowingFunction:&average },
+			"rule 1: windowing requires a positive duration" },
+		{ "zero severity", METRIC_NUMERIC,
+			Rule{ Criteria:CRITERIA_MAX_VALUE, Severity:0, Value:"1" },
+			"rule 1: severity must be between 1 and 5" },
+		{ "high severity", METRIC_NUMERIC,
+			Rule{ Criteria:CRITERIA_MAX_VALUE, Severity:6, Value:"1" },
+			"rule 1: severity must be between 1 and 5" },
+		{ "unknown criteria", METRIC_NUMERIC,
+			Rule{ Criteria:"exceeds", Severity:1, Value:"1" },
+			"rule 1: unknown criteria \"exceeds\"" },
+	}
+
+	for _, test := range tests {
+		rs := RuleSet {
+			CheckCID:		"/check/1",
+			MetricName:	"cpu",
+			MetricType:	test.metric,
+			Rules:			[]Rule{ test.rule },
+		}
+		err := rs.Validate()
+
+		if test.problem == "" {
+			if err != nil {
+				t.Errorf("%s: validation failed unexpectedly: %s\n", test.name, err.Error())
+			}
+			continue
+		}
+
+		var verr ValidationError
+		if !errors.As(err, &verr) {
+			t.Errorf("%s: expected a ValidationError, got %v\n", test.name, err)
+			continue
+		}
+		if len(verr.Problems) != 1 || verr.Problems[0] != test.problem {
+			t.Errorf("%s: expected problem %q, got %q\n", test.name, test.problem, verr.Problems)
+		}
+	}
+
+	// Contact groups must be given for valid severities
+	rs := RuleSet {
+		CheckCID:				"/check/1",
+		MetricName:			"cpu",
+		MetricType:			METRIC_NUMERIC,
+		Rules:					[]Rule{ { Criteria:CRITERIA_MAX_VALUE, Severity:1, Value:"90" } },
+		ContactGroups:	map[string][]CID{ "0":{ "/contact_group/1" }, "critical":{ "/contact_group/2" } },
+	}
+	var verr ValidationError
+	if !errors.As(rs.Validate(), &verr) {
+		t.Fatalf("Expected a ValidationError for invalid contact group severities\n")
+	}
+	expect(t, len(verr.Problems), 2)
+}
+
+
+func TestRuleSetBuilder(t *testing.T) {
+	rs, err := NewRuleSetBuilder("/check/1", "cpu", METRIC_NUMERIC).
+		MaxValue(1, 90).Wait(time.Duration(5) * time.Minute).
+		OnAbsence(2, time.Duration(10) * time.Minute).
+		Notify(1, "/contact_group/1").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed unexpectedly: %s\n", err.Error())
+	}
+	expect(t, rs.Rules[0].Value, "90")
+	expect(t, rs.Rules[0].Wait, 5)
+	expect(t, rs.Rules[1].Value, "600")
+
+	tests := []struct {
+		name			string
+		builder		*RuleSetBuilder
+		problems	[]string
+	}{
+		{ "wait before any rule",
+			NewRuleSetBuilder("/check/1", "cpu", METRIC_NUMERIC).Wait(time.Minute).MaxValue(1, 90),
+			[]string{ "wait given before any rule" } },
+		{ "window before any rule",
+			NewRuleSetBuilder("/check/1", "cpu", METRIC_NUMERIC).Window(WINDOW_AVERAGE, time.Minute).MaxValue(1, 90),
+			[]string{ "window given before any rule" } },
+		{ "sub-minute wait",
+			NewRuleSetBuilder("/check/1", "cpu", METRIC_NUMERIC).MaxValue(1, 90).Wait(time.Duration(30) * time.Second),
+			[]string{ "wait must be a whole number of minutes" } },
+		{ "builder and rule problems",
+			NewRuleSetBuilder("/check/1", "cpu", METRIC_TEXT).Wait(time.Minute).MaxValue(6, 90),
+			[]string{
+				"wait given before any rule",
+				"rule 1: severity must be between 1 and 5",
+				"rule 1: criteria \"max value\" cannot be applied to text metrics",
+			} },
+	}
+
+	for _, test := range tests {
+		_, err := test.builder.Build()
+		var verr ValidationError
+		if !errors.As(err, &verr) {
+			t.Errorf("%s: expected a ValidationError, got %v\n", test.name, err)
+			continue
+		}
+		if len(verr.Problems) != len(test.problems) {
+			t.Errorf("%s: expected problems %q, got %q\n", test.name, test.problems, verr.Problems)
+			continue
+		}
+		for i, problem := range test.problems {
+			expect(t, verr.Problems[i], problem)
+		}
+	}
+}
@@ -0,0 +1,493 @@
+package circonus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Structures ============================================================ //
+
+// A Trap submits metrics to the submission URL of an HTTPTrap check bundle
+// (see CheckBundle.SubmissionURL).  Metrics are recorded in memory and sent
+// together, either periodically or when Flush is called:
+//
+//	trap, err := NewTrap(bundle.SubmissionURL())
+//	defer trap.Close(ctx)
+//
+//	trap.Counter("requests", 1)
+//	trap.Gauge("queue_length", 12)
+//	trap.Histogram("latency", 0.012, 0.018)
+//
+// Each submission holds only the metrics recorded since the last: counters
+// hold their total increase, histograms every sample, and gauges and text
+// metrics their most recent value.  Metrics not recorded again are not
+// resubmitted.  Submissions that fail are retried, and if they still fail
+// their metrics are kept for the next submission.  So that memory does not
+// grow without bound while Circonus is unreachable, metrics are dropped once
+// several submissions in a row have failed (see WithTrapMaxFailures).
+//
+// Traps are safe for concurrent use.
+type Trap struct {
+	url        string
+	httpclient *http.Client
+	interval   time.Duration // If zero, metrics are only sent by Flush
+	onError    func(error)   // Receives errors from periodic submissions
+	retry      RetryPolicy
+	preFlush   func(*Trap) // Records further metrics before each submission
+
+	mutex       sync.Mutex // Guards pending
+	pending     map[string]TrapMetric
+	sending     sync.Mutex // Held while a submission is in progress, guarding failures
+	failures    int        // Submissions failed in a row
+	maxFailures int        // Failures after which pending metrics are dropped
+
+	stop    chan struct{}
+	stopped chan struct{}
+	closing sync.Once
+	cancel  context.CancelFunc // Abandons a periodic submission in progress
+	dropped error              // Metrics dropped by periodic submissions
+}
+
+// A TrapMetric is a single metric in the form Circonus accepts from HTTPTrap
+// checks.  Type is one of the TRAP_* constants.
+type TrapMetric struct {
+	Type  string      `json:"_type"`
+	Value interface{} `json:"_value"`
+}
+
+// A TrapOption configures a Trap as it is created by NewTrap.
+type TrapOption func(*Trap) error
+
+// Constants & Data ====================================================== //
+
+// Types of metric accepted by HTTPTrap checks.
+const (
+	TRAP_INT32     string = "i"
+	TRAP_UINT32    string = "I"
+	TRAP_INT64     string = "l"
+	TRAP_UINT64    string = "L"
+	TRAP_DOUBLE    string = "n"
+	TRAP_TEXT      string = "s"
+	TRAP_HISTOGRAM string = "h"
+)
+
+const (
+	default_trap_interval     time.Duration = time.Duration(10) * time.Second
+	default_trap_timeout      time.Duration = time.Duration(10) * time.Second
+	default_trap_max_failures int           = 5
+)
+
+// Trap API ============================================================== //
+
+// Creates a Trap submitting to the given URL, and starts submitting
+// metrics every ten seconds unless configured otherwise.
+//
+// By default failed submissions are retried after server errors and network
+// failures as well as rate limiting, since HTTPTrap submissions are made with
+// PUT.
+func NewTrap(submissionURL string, options ...TrapOption) (*Trap, error) {
+	u, err := url.Parse(submissionURL)
+	if err != nil {
+		return nil, OptionError{Option: "submission URL", Reason: err.Error()}
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, OptionError{Option: "submission URL", Reason: "scheme must be http or https"}
+	}
+	if u.Host == "" {
+		return nil, OptionError{Option: "submission URL", Reason: "no host name given"}
+	}
+
+	t := &Trap{
+		url:         u.String(),
+		httpclient:  &http.Client{Timeout: default_trap_timeout},
+		interval:    default_trap_interval,
+		retry:       ExponentialBackoff{RetryServerErrors: true},
+		pending:     make(map[string]TrapMetric),
+		maxFailures: default_trap_max_failures,
+		stop:        make(chan struct{}),
+		stopped:     make(chan struct{}),
+	}
+	for _, option := range options {
+		if err := option(t); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+	if t.interval > 0 {
+		go t.run(ctx)
+	} else {
+		cancel()
+		close(t.stopped)
+	}
+	return t, nil
+}
+
+// Adds to the value of a counter.
+func (t *Trap) Counter(name string, delta uint64) {
+	t.Set(name, TrapMetric{Type: TRAP_UINT64, Value: delta})
+}
+
+// Sets the value of a gauge.
+func (t *Trap) Gauge(name string, value float64) {
+	t.Set(name, TrapMetric{Type: TRAP_DOUBLE, Value: value})
+}
+
+// Sets the value of a text metric.
+func (t *Trap) Text(name string, value string) {
+	t.Set(name, TrapMetric{Type: TRAP_TEXT, Value: value})
+}
+
+// Adds samples to a histogram.
+func (t *Trap) Histogram(name string, samples ...float64) {
+	values := make([]interface{}, len(samples))
+	for i, sample := range samples {
+		values[i] = sample
+	}
+	t.Set(name, TrapMetric{Type: TRAP_HISTOGRAM, Value: values})
+}
+
+// Adds pre-aggregated bins to a histogram, each in the form "H[<value>]=<count>"
+// (eg. "H[1.2e+01]=3").
+func (t *Trap) HistogramBins(name string, bins ...string) {
+	values := make([]interface{}, len(bins))
+	for i, bin := range bins {
+		values[i] = bin
+	}
+	t.Set(name, TrapMetric{Type: TRAP_HISTOGRAM, Value: values})
+}
+
+// Records a metric, combining it with any value recorded for the same name
+// since the last submission.
+func (t *Trap) Set(name string, metric TrapMetric) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if previous, exists := t.pending[name]; exists {
+		metric = combine(previous, metric)
+	}
+	t.pending[name] = metric
+}
+
+// Submits every metric recorded since the last submission.  If submission
+// fails, the metrics are kept to be submitted again, unless too many
+// submissions have now failed in a row, in which case they are dropped and
+// a MetricsDroppedError is returned.
+func (t *Trap) Flush(ctx context.Context) error {
+	t.sending.Lock()
+	defer t.sending.Unlock()
+
+	if t.preFlush != nil {
+		t.preFlush(t)
+	}
+
+	t.mutex.Lock()
+	batch := t.pending
+	t.pending = make(map[string]TrapMetric)
+	t.mutex.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	if err := t.submit(ctx, batch); err != nil {
+		if t.failures++; t.failures >= t.maxFailures {
+			t.failures = 0
+			return MetricsDroppedError{Dropped: len(batch), Err: err}
+		}
+		t.restore(batch)
+		return err
+	}
+	t.failures = 0
+	return nil
+}
+
+// Stops periodic submission and submits any remaining metrics.  A periodic
+// submission in progress is allowed to finish unless the context ends first.
+// If periodic submissions have dropped metrics, a MetricsDroppedError is
+// returned alongside any error from the final submission.
+func (t *Trap) Close(ctx context.Context) error {
+	t.closing.Do(func() { close(t.stop) })
+
+	select {
+	case <-t.stopped:
+	case <-ctx.Done():
+		t.cancel()
+		<-t.stopped
+		return errors.Join(t.dropped, RequestCanceledError{Err: ctx.Err()})
+	}
+	return errors.Join(t.dropped, t.Flush(ctx))
+}
+
+// Trap Options ========================================================== //
+
+// Sets the interval between periodic submissions.  An interval of zero
+// disables them, leaving metrics to be submitted by Flush.
+func WithTrapInterval(interval time.Duration) TrapOption {
+	return func(t *Trap) error {
+		if interval < 0 {
+			return OptionError{Option: "WithTrapInterval", Reason: "interval is negative"}
+		}
+		t.interval = interval
+		return nil
+	}
+}
+
+// Sets the HTTP client used to submit metrics, such as one trusting the
+// certificate authority of an enterprise broker.
+func WithTrapHTTPClient(client *http.Client) TrapOption {
+	return func(t *Trap) error {
+		if client == nil {
+			return OptionError{Option: "WithTrapHTTPClient", Reason: "client is nil"}
+		}
+		t.httpclient = client
+		return nil
+	}
+}
+
+// Sets the policy deciding whether and when failed submissions are retried.
+func WithTrapRetryPolicy(policy RetryPolicy) TrapOption {
+	return func(t *Trap) error {
+		if policy == nil {
+			return OptionError{Option: "WithTrapRetryPolicy", Reason: "policy is nil"}
+		}
+		t.retry = policy
+		return nil
+	}
+}
+
+// Sets the number of submissions which may fail in a row before the metrics
+// they hold are dropped, which is five by default.  Metrics recorded while
+// submissions are failing are dropped along with them.
+func WithTrapMaxFailures(failures int) TrapOption {
+	return func(t *Trap) error {
+		if failures < 1 {
+			return OptionError{Option: "WithTrapMaxFailures", Reason: "at least one failure must be allowed"}
+		}
+		t.maxFailures = failures
+		return nil
+	}
+}
+
+// Sets a function to receive the errors of failed periodic submissions,
+// which are otherwise discarded.
+func WithTrapErrorHandler(handler func(error)) TrapOption {
+	return func(t *Trap) error {
+		t.onError = handler
+		return nil
+	}
+}
+
+// Sets a function called before each submission, periodic or not, to record
+// metrics aggregated elsewhere (as by a Metrics registry).
+func withTrapPreFlush(hook func(*Trap)) TrapOption {
+	return func(t *Trap) error {
+		t.preFlush = hook
+		return nil
+	}
+}
+
+// Trap Internals ======================================================== //
+
+// Submits metrics every interval until the Trap is closed.  The context is
+// canceled by Close to abandon a submission in progress.
+func (t *Trap) run(ctx context.Context) {
+	defer close(t.stopped)
+	defer t.cancel()
+
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// Closing takes priority over a tick received at the same time
+			select {
+			case <-t.stop:
+				return
+			default:
+			}
+
+			err := t.Flush(ctx)
+			if _, ok := err.(MetricsDroppedError); ok {
+				t.dropped = errors.Join(t.dropped, err)
+			}
+			if err != nil && t.onError != nil {
+				t.onError(err)
+			}
+		case <-t.stop:
+			return
+		}
+	}
+}
+
+// Submits a batch of metrics, retrying as the Trap's RetryPolicy allows.
+func (t *Trap) submit(ctx context.Context, batch map[string]TrapMetric) error {
+	encoded, err := json.Marshal(batch)
+	if err != nil {
+		return RequestDataError{Reason: err.Error()}
+	}
+
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		status, err := t.trySubmit(ctx, encoded)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return RequestCanceledError{Err: ctx.Err()}
+		}
+
+		a := RetryAttempt{
+			Attempt:    attempt,
+			Elapsed:    time.Since(start),
+			Method:     http.MethodPut,
+			StatusCode: status,
+			Err:        err,
+		}
+		if rl, ok := err.(RateLimitError); ok {
+			a.RetryAfter = rl.RetryAfter
+		}
+
+		delay, retry := t.retry.Backoff(a)
+		if !retry {
+			if rl, ok := err.(RateLimitError); ok {
+				return RateLimitExceededError{api: rl.api}
+			}
+			return err
+		}
+		if werr := wait(ctx, delay); werr != nil {
+			return werr
+		}
+	}
+}
+
+// Makes a single attempt at submitting encoded metrics, returning the
+// response's status code, or zero if none was received.
+func (t *Trap) trySubmit(ctx context.Context, encoded []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, t.url, bytes.NewReader(encoded))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := t.httpclient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	// Failures are reported with the same error types as API requests
+	if res.StatusCode > 299 {
+		return res.StatusCode, responseError(req, res, request{Method: http.MethodPut, Resource: t.url})
+	}
+	io.Copy(io.Discard, res.Body)
+	return res.StatusCode, nil
+}
+
+// Returns metrics from a failed submission to those pending, combined with
+// any recorded since.
+func (t *Trap) restore(batch map[string]TrapMetric) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for name, metric := range batch {
+		if newer, exists := t.pending[name]; exists {
+			metric = combine(metric, newer)
+		}
+		t.pending[name] = metric
+	}
+}
+
+// Combines two values recorded for the same metric: counters are summed and
+// histogram samples gathered, while anything else takes the newer value.
+// Histograms given as a Histogram are gathered as their bins.
+// Histograms recorded both as samples and as bins are converted to bins, as
+// HTTPTrap checks do not accept a mixture of the two.
+func combine(older TrapMetric, newer TrapMetric) TrapMetric {
+	if older.Type != newer.Type {
+		return newer
+	}
+
+	switch newer.Type {
+	case TRAP_UINT64:
+		a, aok := older.Value.(uint64)
+		b, bok := newer.Value.(uint64)
+		if aok && bok {
+			return TrapMetric{Type: TRAP_UINT64, Value: a + b}
+		}
+	case TRAP_HISTOGRAM:
+		a, aok := histogramValues(older.Value)
+		b, bok := histogramValues(newer.Value)
+		if aok && bok {
+			values := make([]interface{}, 0, len(a)+len(b))
+			values = append(append(values, a...), b...)
+			if binned(a) != binned(b) {
+				values = toBins(values)
+			}
+			return TrapMetric{Type: TRAP_HISTOGRAM, Value: values}
+		}
+	}
+	return newer
+}
+
+// Returns the samples or bins of a histogram metric's value, converting a
+// Histogram to its bins.
+func histogramValues(value interface{}) ([]interface{}, bool) {
+	switch v := value.(type) {
+	case []interface{}:
+		return v, true
+	case *Histogram:
+		bins := v.Bins()
+		values := make([]interface{}, len(bins))
+		for i, bin := range bins {
+			values[i] = bin
+		}
+		return values, true
+	}
+	return nil, false
+}
+
+// Reports whether histogram values are given as bins rather than samples.
+func binned(values []interface{}) bool {
+	for _, value := range values {
+		if _, ok := value.(string); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Converts histogram samples to bins, merging them with any bins given.
+// Malformed bins are passed through for Circonus to reject.
+func toBins(values []interface{}) []interface{} {
+	h := NewHistogram()
+	var bins []interface{}
+	for _, value := range values {
+		switch v := value.(type) {
+		case float64:
+			h.Record(v)
+		case string:
+			if parsed, err := ParseHistogram(v); err == nil {
+				h.Merge(parsed)
+			} else {
+				bins = append(bins, v)
+			}
+		default:
+			bins = append(bins, v)
+		}
+	}
+	for _, bin := range h.Bins() {
+		bins = append(bins, bin)
+	}
+	return bins
+}
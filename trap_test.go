@@ -0,0 +1,309 @@
+package circonus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+
+// Trap Server =========================================================== //
+
+
+/*
+ * An HTTPTrap endpoint which decodes each submission it receives, failing
+ * with a 500 response while failures remain.
+ */
+type trapServer struct {
+	*httptest.Server
+	mutex				sync.Mutex
+	failures		int
+	submissions	chan map[string]TrapMetric
+}
+
+
+func createTrapServer(failures int) *trapServer {
+	server := &trapServer {
+		failures:			failures,
+		submissions:	make(chan map[string]TrapMetric, 10),
+	}
+
+	handler := func(res http.ResponseWriter, req *http.Request) {
+		server.mutex.Lock()
+		fail := server.failures > 0
+		if fail {
+			server.failures--
+		}
+		server.mutex.Unlock()
+
+		if fail {
+			respond(res, http.StatusInternalServerError, "{}")
+			return
+		}
+		if req.Method != http.MethodPut {
+			respond(res, http.StatusMethodNotAllowed, "{}")
+			return
+		}
+
+		var submission map[string]TrapMetric
+		if err := json.NewDecoder(req.Body).Decode(&submission); err != nil {
+			respond(res, http.StatusBadRequest, "{}")
+			return
+		}
+		server.submissions <- submission
+		respond(res, http.StatusOK, `{"stats":1}`)
+	}
+
+	server.Server = httptest.NewServer(http.HandlerFunc(handler))
+	return server
+}
+
+
+/*
+ * Creates a Trap which only submits when flushed, and retries quickly.
+ */
+func createTrap(t *testing.T, url string) *Trap {
+	trap, err := NewTrap(url,
+		WithTrapInterval(0),
+		WithTrapRetryPolicy(ExponentialBackoff {
+			MaxAttempts:				3,
+			InitialInterval:		time.Duration(10) * time.Millisecond,
+			RetryServerErrors:	true,
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Cannot create trap: %s\n", err.Error())
+	}
+	return trap
+}
+
+
+// Tests ================================================================= //
+
+
+func TestTrapSubmission(t *testing.T) {
+	server := createTrapServer(0)
+	defer server.Close()
+	trap := createTrap(t, server.URL + "/module/httptrap/uuid/secret")
+	ctx := context.Background()
+
+	trap.Counter("requests", 2)
+	trap.Counter("requests", 3)
+	trap.Gauge("queue", 4)
+	trap.Gauge("queue", 7.5)
+	trap.Text("version", "1.2.3")
+	trap.Histogram("latency", 1, 2)
+	trap.HistogramBins("latency", "H[1.2e+01]=3")
+
+	if err := trap.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed unexpectedly: %s\n", err.Error())
+	}
+
+	submission := <- server.submissions
+	expect(t, len(submission), 4)
+	expect(t, submission["requests"].Type, TRAP_UINT64)
+	expect(t, submission["requests"].Value, float64(5))
+	expect(t, submission["queue"].Type, TRAP_DOUBLE)
+	expect(t, submission["queue"].Value, 7.5)
+	expect(t, submission["version"].Type, TRAP_TEXT)
+	expect(t, submission["version"].Value, "1.2.3")
+	expect(t, submission["latency"].Type, TRAP_HISTOGRAM)
+	latency := submission["latency"].Value.([]interface{})
+	expect(t, len(latency), 3)
+	expect(t, latency[0], "H[1.0e+00]=1")
+	expect(t, latency[1], "H[2.0e+00]=1")
+	expect(t, latency[2], "H[1.2e+01]=3")
+
+	// Only metrics recorded since the last submission are sent, and
+	// histograms recorded only as samples are sent as samples
+	trap.Counter("requests", 1)
+	trap.Histogram("latency", 12, 12)
+	if err := trap.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed unexpectedly: %s\n", err.Error())
+	}
+	submission = <- server.submissions
+	expect(t, len(submission), 2)
+	expect(t, submission["requests"].Value, float64(1))
+	expect(t, submission["latency"].Value.([]interface{})[1], float64(12))
+
+	// Histograms given as a Histogram are combined with each other and with
+	// samples, as bins
+	h1, h2 := NewHistogram(), NewHistogram()
+	h1.Record(1)
+	h2.Record(1)
+	h2.Record(2)
+	trap.Set("latency", TrapMetric { Type:TRAP_HISTOGRAM, Value:h1 })
+	trap.Set("latency", TrapMetric { Type:TRAP_HISTOGRAM, Value:h2 })
+	trap.Histogram("latency", 3)
+	if err := trap.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed unexpectedly: %s\n", err.Error())
+	}
+	latency = (<- server.submissions)["latency"].Value.([]interface{})
+	expect(t, len(latency), 3)
+	expect(t, latency[0], "H[1.0e+00]=2")
+	expect(t, latency[1], "H[2.0e+00]=1")
+	expect(t, latency[2], "H[3.0e+00]=1")
+
+	// Nothing is sent when nothing has been recorded
+	if err := trap.Flush(ctx); err != nil {
+		t.Errorf("Empty flush failed unexpectedly: %s\n", err.Error())
+	}
+	select {
+	case <- server.submissions:
+		t.Errorf("Empty flush was submitted\n")
+	default:
+	}
+}
+
+
+func TestTrapRetry(t *testing.T) {
+	server := createTrapServer(2)
+	defer server.Close()
+	trap := createTrap(t, server.URL)
+	ctx := context.Background()
+
+	trap.Counter("requests", 1)
+	if err := trap.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed unexpectedly: %s\n", err.Error())
+	}
+	expect(t, (<- server.submissions)["requests"].Value, float64(1))
+
+	// Metrics are kept when retries are exhausted
+	server.mutex.Lock()
+	server.failures = 3
+	server.mutex.Unlock()
+	trap.Counter("requests", 1)
+	err := trap.Flush(ctx)
+	if !errors.Is(err, ErrServer) {
+		t.Fatalf("Expected a server error, got %v\n", err)
+	}
+	var merr MalformedResponseError
+	var api *APIError
+	if !errors.As(err, &merr) || !errors.As(err, &api) {
+		t.Errorf("Expected a MalformedResponseError wrapping an APIError, got %v\n", err)
+	} else {
+		expect(t, api.StatusCode, http.StatusInternalServerError)
+		expect(t, api.Method, http.MethodPut)
+	}
+
+	trap.Counter("requests", 2)
+	if err := trap.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed unexpectedly: %s\n", err.Error())
+	}
+	expect(t, (<- server.submissions)["requests"].Value, float64(3))
+}
+
+
+func TestTrapDropped(t *testing.T) {
+	server := createTrapServer(100)
+	defer server.Close()
+	trap, err := NewTrap(server.URL,
+		WithTrapInterval(0),
+		WithTrapMaxFailures(2),
+		WithTrapRetryPolicy(ExponentialBackoff { MaxAttempts:1, RetryServerErrors:true }),
+	)
+	if err != nil {
+		t.Fatalf("Cannot create trap: %s\n", err.Error())
+	}
+	ctx := context.Background()
+
+	// Metrics are kept after a first failure, and dropped after a second
+	trap.Counter("requests", 1)
+	trap.Histogram("latency", 1, 2, 3)
+	if err := trap.Flush(ctx); errors.Is(err, ErrMetricsDropped) || !errors.Is(err, ErrServer) {
+		t.Fatalf("Expected a server error, got %v\n", err)
+	}
+	trap.Gauge("queue", 4)
+	err = trap.Flush(ctx)
+	var derr MetricsDroppedError
+	if !errors.As(err, &derr) || !errors.Is(err, ErrServer) {
+		t.Fatalf("Expected a MetricsDroppedError, got %v\n", err)
+	}
+	expect(t, derr.Dropped, 3)
+
+	// Submission resumes afresh once the server recovers
+	server.mutex.Lock()
+	server.failures = 0
+	server.mutex.Unlock()
+	trap.Counter("requests", 2)
+	if err := trap.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed unexpectedly: %s\n", err.Error())
+	}
+	submission := <- server.submissions
+	expect(t, len(submission), 1)
+	expect(t, submission["requests"].Value, float64(2))
+
+	if _, err := NewTrap(server.URL, WithTrapMaxFailures(0)); err == nil {
+		t.Errorf("Trap accepted a maximum of zero failures\n")
+	}
+}
+
+
+func TestTrapInterval(t *testing.T) {
+	server := createTrapServer(0)
+	defer server.Close()
+
+	trap, err := NewTrap(server.URL, WithTrapInterval(time.Duration(20) * time.Millisecond))
+	if err != nil {
+		t.Fatalf("Cannot create trap: %s\n", err.Error())
+	}
+
+	trap.Gauge("queue", 1)
+	select {
+	case submission := <- server.submissions:
+		expect(t, submission["queue"].Value, float64(1))
+	case <- time.After(time.Second):
+		t.Errorf("Metrics were not submitted periodically\n")
+	}
+
+	// Closing submits any remaining metrics
+	trap.Gauge("queue", 2)
+	if err := trap.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed unexpectedly: %s\n", err.Error())
+	}
+	expect(t, (<- server.submissions)["queue"].Value, float64(2))
+
+	if _, err := NewTrap("ftp://example.com"); err == nil {
+		t.Errorf("Trap accepted a submission URL with an unsupported scheme\n")
+	}
+}
+
+
+func TestTrapClose(t *testing.T) {
+	server := createTrapServer(1000)
+	defer server.Close()
+
+	// Each periodic submission retries for far longer than Close will wait
+	trap, err := NewTrap(server.URL,
+		WithTrapInterval(time.Duration(10) * time.Millisecond),
+		WithTrapMaxFailures(1),
+		WithTrapRetryPolicy(ExponentialBackoff {
+			InitialInterval:		time.Minute,
+			RetryServerErrors:	true,
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Cannot create trap: %s\n", err.Error())
+	}
+
+	trap.Gauge("queue", 1)
+	time.Sleep(time.Duration(50) * time.Millisecond)
+
+	// Closing abandons the submission in progress, reporting its metrics
+	// as dropped
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(100) * time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = trap.Close(ctx)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Close took %s despite its context ending\n", elapsed)
+	}
+	if !errors.Is(err, ErrMetricsDropped) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected dropped metrics and an expired context, got %v\n", err)
+	}
+}